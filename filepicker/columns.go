@@ -0,0 +1,53 @@
+package filepicker
+
+import (
+	"io/fs"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+// sizeOf formats the size of a file, either human-readable or in bytes.
+func (m Model) sizeOf(info fs.FileInfo) string {
+	if m.ExactSize {
+		return humanize.Comma(info.Size())
+	}
+	return strings.Replace(humanize.Bytes(uint64(info.Size())), " ", "", 1) //nolint:gosec
+}
+
+// modTimeOf formats the modification time of a file with ModTimeFormat, or
+// relative to now if it's empty.
+func (m Model) modTimeOf(info fs.FileInfo) string {
+	if m.ModTimeFormat == "" {
+		return humanize.Time(info.ModTime())
+	}
+	return info.ModTime().Format(m.ModTimeFormat)
+}
+
+// columnWidths returns the widths of the size, modification time and owner
+// columns, fitting the files in view.
+func (m Model) columnWidths() (size, modTime, owner int) {
+	size = m.Styles.FileSize.GetWidth()
+	for i := max(m.min, 0); i <= min(m.max, len(m.files)-1); i++ {
+		info, err := m.files[i].Info()
+		if err != nil {
+			continue
+		}
+		if m.ShowSize {
+			size = max(size, lipgloss.Width(m.sizeOf(info)))
+		}
+		if m.ShowModTime {
+			modTime = max(modTime, lipgloss.Width(m.modTimeOf(info)))
+		}
+		if m.ShowOwner {
+			owner = max(owner, lipgloss.Width(ownerOf(info)))
+		}
+	}
+	return size, modTime, owner
+}
+
+// padRight pads s with spaces to the given width.
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-lipgloss.Width(s)))
+}
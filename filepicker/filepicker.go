@@ -6,15 +6,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/dustin/go-humanize"
 )
 
 var lastID int64
@@ -25,10 +24,19 @@ func nextID() int {
 
 // New returns a new filepicker model with default styling and key bindings.
 func New() Model {
+	pathInput := textinput.New()
+	pathInput.Prompt = "Go to: "
+	pathInput.ShowSuggestions = true
+
+	nameInput := textinput.New()
+	nameInput.Prompt = "Save as: "
+
 	return Model{
 		id:               nextID(),
 		CurrentDirectory: ".",
+		FS:               OSFS{},
 		Cursor:           ">",
+		Marker:           "✓",
 		AllowedTypes:     []string{},
 		selected:         0,
 		ShowPermissions:  true,
@@ -37,12 +45,18 @@ func New() Model {
 		DirAllowed:       false,
 		FileAllowed:      true,
 		AutoHeight:       true,
+		QuickJump:        true,
+		PreviewLines:     defaultPreviewLines,
+		PreviewMaxBytes:  defaultPreviewMaxBytes,
+		PreviewWidth:     defaultPreviewWidth,
 		Height:           0,
 		max:              0,
 		min:              0,
 		selectedStack:    newStack(),
 		minStack:         newStack(),
 		maxStack:         newStack(),
+		PathInput:        pathInput,
+		NameInput:        nameInput,
 		KeyMap:           DefaultKeyMap(),
 		Styles:           DefaultStyles(),
 	}
@@ -54,6 +68,7 @@ type errorMsg struct {
 
 type readDirMsg struct {
 	id      int
+	path    string
 	entries []os.DirEntry
 }
 
@@ -74,6 +89,38 @@ type KeyMap struct {
 	Back     key.Binding
 	Open     key.Binding
 	Select   key.Binding
+
+	// Multi-select.
+	ToggleMark key.Binding
+
+	// Path input.
+	EnterPath  key.Binding
+	AcceptPath key.Binding
+	CancelPath key.Binding
+
+	// Save mode.
+	EditName         key.Binding
+	NewFolder        key.Binding
+	AcceptName       key.Binding
+	CancelName       key.Binding
+	ConfirmOverwrite key.Binding
+	CancelOverwrite  key.Binding
+
+	// Sorting.
+	CycleSort   key.Binding
+	ReverseSort key.Binding
+
+	// Tree view.
+	Expand   key.Binding
+	Collapse key.Binding
+
+	// Filtering.
+	Filter       key.Binding
+	ClearFilter  key.Binding
+	AcceptFilter key.Binding
+
+	// Quick-jump.
+	Jump key.Binding
 }
 
 // DefaultKeyMap defines the default keybindings.
@@ -88,6 +135,38 @@ func DefaultKeyMap() KeyMap {
 		Back:     key.NewBinding(key.WithKeys("h", "backspace", "left", "esc"), key.WithHelp("h", "back")),
 		Open:     key.NewBinding(key.WithKeys("l", "right", "enter"), key.WithHelp("l", "open")),
 		Select:   key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select")),
+
+		// Multi-select.
+		ToggleMark: key.NewBinding(key.WithKeys(" ", "x"), key.WithHelp("space/x", "mark")),
+
+		// Path input.
+		EnterPath:  key.NewBinding(key.WithKeys(":", "ctrl+l"), key.WithHelp(":", "go to path")),
+		AcceptPath: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "go")),
+		CancelPath: key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+
+		// Save mode.
+		EditName:         key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "edit name")),
+		NewFolder:        key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "new folder")),
+		AcceptName:       key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "save")),
+		CancelName:       key.NewBinding(key.WithKeys("esc", "tab"), key.WithHelp("esc", "back to files")),
+		ConfirmOverwrite: key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "overwrite")),
+		CancelOverwrite:  key.NewBinding(key.WithKeys("n", "esc"), key.WithHelp("n", "cancel")),
+
+		// Sorting.
+		CycleSort:   key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort by")),
+		ReverseSort: key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "reverse sort")),
+
+		// Tree view.
+		Expand:   key.NewBinding(key.WithKeys("l", "right"), key.WithHelp("l", "expand")),
+		Collapse: key.NewBinding(key.WithKeys("h", "left"), key.WithHelp("h", "collapse")),
+
+		// Filtering.
+		Filter:       key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "filter")),
+		ClearFilter:  key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "clear filter")),
+		AcceptFilter: key.NewBinding(key.WithKeys("enter", "tab"), key.WithHelp("enter", "apply filter")),
+
+		// Quick-jump.
+		Jump: key.NewBinding(key.WithKeys("'"), key.WithHelp("'", "jump to letter")),
 	}
 }
 
@@ -104,6 +183,16 @@ type Styles struct {
 	DisabledSelected lipgloss.Style
 	FileSize         lipgloss.Style
 	EmptyDirectory   lipgloss.Style
+	FilterPrompt     lipgloss.Style
+	FilterMatch      lipgloss.Style
+	Marked           lipgloss.Style
+	MarkedCount      lipgloss.Style
+	TreeGuide        lipgloss.Style
+	Preview          lipgloss.Style
+	PreviewMeta      lipgloss.Style
+	ModTime          lipgloss.Style
+	Owner            lipgloss.Style
+	PathError        lipgloss.Style
 }
 
 // DefaultStyles defines the default styling for the file picker.
@@ -126,6 +215,16 @@ func DefaultStylesWithRenderer(r *lipgloss.Renderer) Styles {
 		Selected:         r.NewStyle().Foreground(lipgloss.Color("212")).Bold(true),
 		FileSize:         r.NewStyle().Foreground(lipgloss.Color("240")).Width(fileSizeWidth).Align(lipgloss.Right),
 		EmptyDirectory:   r.NewStyle().Foreground(lipgloss.Color("240")).PaddingLeft(paddingLeft).SetString("Bummer. No Files Found."),
+		FilterPrompt:     r.NewStyle().Foreground(lipgloss.Color("212")).PaddingLeft(paddingLeft),
+		FilterMatch:      r.NewStyle().Underline(true),
+		Marked:           r.NewStyle().Foreground(lipgloss.Color("212")),
+		MarkedCount:      r.NewStyle().Foreground(lipgloss.Color("240")).PaddingLeft(paddingLeft),
+		TreeGuide:        r.NewStyle().Foreground(lipgloss.Color("240")),
+		Preview:          r.NewStyle().Border(lipgloss.NormalBorder(), false, false, false, true).BorderForeground(lipgloss.Color("240")).PaddingLeft(1).MarginLeft(1),
+		PreviewMeta:      r.NewStyle().Foreground(lipgloss.Color("240")),
+		ModTime:          r.NewStyle().Foreground(lipgloss.Color("240")),
+		Owner:            r.NewStyle().Foreground(lipgloss.Color("244")),
+		PathError:        r.NewStyle().Foreground(lipgloss.Color("203")),
 	}
 }
 
@@ -139,6 +238,10 @@ type Model struct {
 	// CurrentDirectory is the directory that the user is currently in.
 	CurrentDirectory string
 
+	// FS is the filesystem being browsed. If nil, the operating system's
+	// filesystem is used.
+	FS FS
+
 	// AllowedTypes specifies which file types the user may select.
 	// If empty the user may select any file.
 	AllowedTypes []string
@@ -151,6 +254,121 @@ type Model struct {
 	DirAllowed      bool
 	FileAllowed     bool
 
+	// ShowModTime and ShowOwner show the modification time and the owner of
+	// files. Modification times are formatted with ModTimeFormat, or relative
+	// to now if it's empty. Owners are only known on Unix.
+	ShowModTime   bool
+	ShowOwner     bool
+	ModTimeFormat string
+
+	// ExactSize shows sizes in bytes rather than human-readable.
+	ExactSize bool
+
+	// SortOrder is the order in which files are listed, reversed if
+	// SortDescending is set. Use SetSortOrder to change it once files are
+	// loaded.
+	SortOrder      SortOrder
+	SortDescending bool
+
+	// allFiles are all files in the current directory, files are those
+	// matching the filter.
+	allFiles []os.DirEntry
+	matches  [][]int
+
+	// PrefixFilter makes the filter match file names starting with the
+	// filter rather than fuzzy-matching them.
+	PrefixFilter bool
+
+	// QuickJump moves the cursor to the next file starting with a typed
+	// letter. Letters bound to keys, such as j, k, s or S with the default
+	// KeyMap, keep their binding; any letter can be jumped to by typing it
+	// after the Jump key.
+	QuickJump bool
+
+	filter    string
+	filtering bool
+	jumping   bool
+
+	// PathInput is where the user types a path to go to, with the names of
+	// files completed from the filesystem.
+	PathInput textinput.Model
+
+	enteringPath  bool
+	pathErr       error
+	completionDir string
+
+	// pathEntered is set when the last key press selected a path typed into
+	// the path input.
+	pathEntered bool
+
+	// SaveMode turns the file picker into a save dialog: the user navigates
+	// to a directory and types the name of the file to save, or picks an
+	// existing file to overwrite after confirming. Names without one of the
+	// AllowedTypes get the first one appended. Paths typed into the path
+	// input are chosen the same way, and don't select files with
+	// DidSelectFile. See DidChooseSavePath.
+	SaveMode bool
+
+	// NameInput is where the user types the name of the file to save, or of
+	// a folder to create, in save mode.
+	NameInput textinput.Model
+
+	editingName    bool
+	creatingFolder bool
+	saveName       string
+	saveErr        error
+
+	// overwritePath is the existing file the user is asked to overwrite.
+	overwritePath string
+
+	// saveChosen is set when the last key press chose the path to save to.
+	saveChosen bool
+
+	// keyConsumed is set when the last key press was handled by the filter
+	// or the path input, so it's not mistaken for a selection.
+	keyConsumed bool
+
+	// MultiSelect allows marking several files, possibly in different
+	// directories, to be returned together by DidSelectFiles. Once files are
+	// marked, the Select key confirms the selection rather than opening
+	// directories.
+	MultiSelect bool
+
+	// MaxSelected limits how many files can be marked. Zero means no limit.
+	MaxSelected int
+
+	// marked are the paths of the marked files, in the order they were
+	// marked.
+	marked []string
+
+	// TreeView shows the current directory as a tree, in which directories
+	// are expanded inline with the Expand and Collapse keys. These take
+	// precedence over Open and Back for keys bound to both, except that
+	// Collapse goes back like Back on collapsed files of the current
+	// directory. It may be turned on or off at any time.
+	TreeView bool
+
+	// children are the loaded files of the current directory and its
+	// subdirectories by path, expanded are the expanded directories.
+	// treeRoot is the directory they were loaded for.
+	treeRoot string
+	children map[string][]os.DirEntry
+	expanded map[string]bool
+
+	// ShowPreview shows a preview of the file under the cursor next to the
+	// files: its metadata, and the first PreviewLines lines of text files or
+	// the files in directories. Previews are loaded in the background, reading
+	// at most PreviewMaxBytes of a file.
+	ShowPreview     bool
+	PreviewLines    int
+	PreviewMaxBytes int64
+
+	// PreviewWidth is the width of the preview pane, including its border,
+	// margin and padding.
+	PreviewWidth int
+
+	preview preview
+
 	FileSelected  string
 	selected      int
 	selectedStack stack
@@ -167,6 +385,10 @@ type Model struct {
 	AutoHeight bool
 
 	Cursor string
+
+	// Marker is shown next to marked files in multi-select mode.
+	Marker string
+
 	Styles Styles
 }
 
@@ -205,31 +427,27 @@ func (m *Model) popView() (int, int, int) {
 
 func (m Model) readDir(path string, showHidden bool) tea.Cmd {
 	return func() tea.Msg {
-		dirEntries, err := os.ReadDir(path)
+		fsys := m.fileSystem()
+		dirEntries, err := fsys.ReadDir(path)
 		if err != nil {
 			return errorMsg{err}
 		}
 
-		sort.Slice(dirEntries, func(i, j int) bool {
-			if dirEntries[i].IsDir() == dirEntries[j].IsDir() {
-				return dirEntries[i].Name() < dirEntries[j].Name()
-			}
-			return dirEntries[i].IsDir()
-		})
+		sortFiles(dirEntries, m.SortOrder, m.SortDescending)
 
 		if showHidden {
-			return readDirMsg{id: m.id, entries: dirEntries}
+			return readDirMsg{id: m.id, path: path, entries: dirEntries}
 		}
 
 		var sanitizedDirEntries []os.DirEntry
 		for _, dirEntry := range dirEntries {
-			isHidden, _ := IsHidden(dirEntry.Name())
+			isHidden, _ := fsys.IsHidden(filepath.Join(path, dirEntry.Name()))
 			if isHidden {
 				continue
 			}
 			sanitizedDirEntries = append(sanitizedDirEntries, dirEntry)
 		}
-		return readDirMsg{id: m.id, entries: sanitizedDirEntries}
+		return readDirMsg{id: m.id, path: path, entries: sanitizedDirEntries}
 	}
 }
 
@@ -248,12 +466,50 @@ func (m *Model) SetHeight(height int) {
 
 // Update handles user interactions within the file picker model.
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	treeCmd := m.syncTreeView()
+	m, cmd := m.update(msg)
+	return m, tea.Batch(treeCmd, cmd, m.updatePreview())
+}
+
+func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
+	m.keyConsumed = false
+	m.pathEntered = false
+	m.saveChosen = false
+
 	switch msg := msg.(type) {
+	case completionMsg:
+		if msg.id != m.id || !m.enteringPath || msg.dir != m.completionDir {
+			break
+		}
+		m.PathInput.SetSuggestions(msg.names)
+	case previewMsg:
+		if msg.id != m.id || msg.path != m.preview.path {
+			break
+		}
+		m.preview = preview{
+			path:      msg.path,
+			info:      msg.info,
+			content:   msg.content,
+			err:       msg.err,
+			entryInfo: m.preview.entryInfo,
+		}
 	case readDirMsg:
 		if msg.id != m.id {
 			break
 		}
-		m.files = msg.entries
+		if m.TreeView {
+			m.updateTree(msg)
+			break
+		}
+		if msg.path != m.CurrentDirectory {
+			break
+		}
+		m.allFiles, m.children = msg.entries, nil
+		if m.filter != "" {
+			m.applyFilter()
+			break
+		}
+		m.files, m.matches = msg.entries, nil
 		m.max = max(m.max, m.Height-1)
 	case tea.WindowSizeMsg:
 		if m.AutoHeight {
@@ -261,7 +517,60 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 		m.max = m.Height - 1
 	case tea.KeyMsg:
+		if m.enteringPath {
+			m.keyConsumed = true
+			return m, m.updatePathInput(msg)
+		}
+		if m.editingName || m.overwritePath != "" {
+			m.keyConsumed = true
+			return m, m.updateSave(msg)
+		}
+		if m.filtering {
+			m.keyConsumed = true
+			m.updateFiltering(msg)
+			break
+		}
+		if m.jumping {
+			m.keyConsumed = true
+			m.jumping = false
+			if msg.Type == tea.KeyRunes && len(msg.Runes) == 1 && !msg.Alt {
+				m.quickJump(msg.Runes[0])
+			}
+			break
+		}
+
 		switch {
+		case key.Matches(msg, m.KeyMap.EnterPath):
+			m.keyConsumed = true
+			return m, m.startPathInput()
+		case m.SaveMode && key.Matches(msg, m.KeyMap.EditName):
+			return m, m.startNameInput(false)
+		case m.SaveMode && key.Matches(msg, m.KeyMap.NewFolder):
+			return m, m.startNameInput(true)
+		case m.SaveMode && len(m.files) > 0 && !m.resolvesToDir(m.files[m.selected]) &&
+			key.Matches(msg, m.KeyMap.Select):
+			return m, m.useSelectedName()
+		case m.QuickJump && key.Matches(msg, m.KeyMap.Jump):
+			m.keyConsumed = true
+			m.jumping = true
+		case key.Matches(msg, m.KeyMap.Filter):
+			m.keyConsumed = true
+			m.filtering = true
+		case m.filter != "" && key.Matches(msg, m.KeyMap.ClearFilter):
+			m.keyConsumed = true
+			m.SetFilter("")
+		case key.Matches(msg, m.KeyMap.CycleSort):
+			m.SetSortOrder(m.SortOrder.Next(), m.SortDescending)
+		case key.Matches(msg, m.KeyMap.ReverseSort):
+			m.SetSortOrder(m.SortOrder, !m.SortDescending)
+		case m.TreeView && key.Matches(msg, m.KeyMap.Expand):
+			return m, m.expand()
+		case m.TreeView && m.canCollapse() && key.Matches(msg, m.KeyMap.Collapse):
+			m.collapse()
+		case m.MultiSelect && key.Matches(msg, m.KeyMap.ToggleMark):
+			m.toggleMark()
+		case m.MultiSelect && len(m.marked) > 0 && key.Matches(msg, m.KeyMap.Select):
+			// The marked files were selected, see DidSelectFiles.
 		case key.Matches(msg, m.KeyMap.GoToTop):
 			m.selected = 0
 			m.min = 0
@@ -314,6 +623,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			}
 		case key.Matches(msg, m.KeyMap.Back):
 			m.CurrentDirectory = filepath.Dir(m.CurrentDirectory)
+			m.filter = ""
 			if m.selectedStack.Length() > 0 {
 				m.selected, m.min, m.max = m.popView()
 			} else {
@@ -336,8 +646,8 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			isDir := f.IsDir()
 
 			if isSymlink {
-				symlinkPath, _ := filepath.EvalSymlinks(filepath.Join(m.CurrentDirectory, f.Name()))
-				info, err := os.Stat(symlinkPath)
+				symlinkPath, _ := m.fileSystem().EvalSymlinks(m.pathOf(f))
+				info, err := m.fileSystem().Stat(symlinkPath)
 				if err != nil {
 					break
 				}
@@ -349,7 +659,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			if (!isDir && m.FileAllowed) || (isDir && m.DirAllowed) {
 				if key.Matches(msg, m.KeyMap.Select) {
 					// Select the current path as the selection
-					m.Path = filepath.Join(m.CurrentDirectory, f.Name())
+					m.Path = m.pathOf(f)
 				}
 			}
 
@@ -357,37 +667,68 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 				break
 			}
 
-			m.CurrentDirectory = filepath.Join(m.CurrentDirectory, f.Name())
+			m.CurrentDirectory = m.pathOf(f)
+			m.filter = ""
 			m.pushView(m.selected, m.min, m.max)
 			m.selected = 0
 			m.min = 0
 			m.max = m.Height - 1
 			return m, m.readDir(m.CurrentDirectory, m.ShowHidden)
+		default:
+			if m.QuickJump && msg.Type == tea.KeyRunes && len(msg.Runes) == 1 && !msg.Alt {
+				m.quickJump(msg.Runes[0])
+			}
+		}
+	default:
+		var cmd tea.Cmd
+		if m.enteringPath {
+			m.PathInput, cmd = m.PathInput.Update(msg)
+		} else if m.editingName {
+			m.NameInput, cmd = m.NameInput.Update(msg)
 		}
+		return m, cmd
 	}
 	return m, nil
 }
 
 // View returns the view of the file picker.
 func (m Model) View() string {
+	// The status line takes the place of the last row of files.
+	status := m.statusView()
+	height, first, last := m.Height, m.min, m.max
+	if status != "" && m.Height > 1 {
+		height--
+		if last-first >= height {
+			if m.selected == last {
+				first++
+			} else {
+				last--
+			}
+		}
+	}
+
 	if len(m.files) == 0 {
+		if status != "" {
+			return m.Styles.EmptyDirectory.Height(height).MaxHeight(height).String() + "\n" + status
+		}
 		return m.Styles.EmptyDirectory.Height(m.Height).MaxHeight(m.Height).String()
 	}
 	var s strings.Builder
+	sizeWidth, modTimeWidth, ownerWidth := m.columnWidths()
 
 	for i, f := range m.files {
-		if i < m.min || i > m.max {
+		if i < first || i > last {
 			continue
 		}
 
 		var symlinkPath string
 		info, _ := f.Info()
 		isSymlink := info.Mode()&os.ModeSymlink != 0
-		size := strings.Replace(humanize.Bytes(uint64(info.Size())), " ", "", 1) //nolint:gosec
+		size := m.sizeOf(info)
 		name := f.Name()
 
 		if isSymlink {
-			symlinkPath, _ = filepath.EvalSymlinks(filepath.Join(m.CurrentDirectory, name))
+			symlinkPath, _ = m.fileSystem().EvalSymlinks(m.pathOf(f))
 		}
 
 		disabled := !m.canSelect(name) && !f.IsDir()
@@ -398,16 +739,23 @@ func (m Model) View() string {
 				selected += " " + info.Mode().String()
 			}
 			if m.ShowSize {
-				selected += fmt.Sprintf("%"+strconv.Itoa(m.Styles.FileSize.GetWidth())+"s", size)
+				selected += fmt.Sprintf("%"+strconv.Itoa(sizeWidth)+"s", size)
 			}
-			selected += " " + name
-			if isSymlink {
-				selected += " → " + symlinkPath
+			if m.ShowModTime {
+				selected += " " + padRight(m.modTimeOf(info), modTimeWidth)
+			}
+			if m.ShowOwner {
+				selected += " " + padRight(ownerOf(info), ownerWidth)
 			}
+			selected += " "
+			cursor, style := m.Styles.Cursor, m.Styles.Selected
 			if disabled {
-				s.WriteString(m.Styles.DisabledSelected.Render(m.Cursor) + m.Styles.DisabledSelected.Render(selected))
-			} else {
-				s.WriteString(m.Styles.Cursor.Render(m.Cursor) + m.Styles.Selected.Render(selected))
+				cursor, style = m.Styles.DisabledSelected, m.Styles.DisabledSelected
+			}
+			s.WriteString(cursor.Render(m.Cursor) + m.markerView(m.pathOf(f)) + style.Render(selected) +
+				m.Styles.TreeGuide.Render(guideOf(f)) + m.renderName(i, name, style))
+			if isSymlink {
+				s.WriteString(style.Render(" → " + symlinkPath))
 			}
 			s.WriteRune('\n')
 			continue
@@ -422,8 +770,8 @@ func (m Model) View() string {
 			style = m.Styles.DisabledFile
 		}
 
-		fileName := style.Render(name)
-		s.WriteString(m.Styles.Cursor.Render(" "))
+		fileName := m.renderName(i, name, style)
+		s.WriteString(m.Styles.Cursor.Render(" ") + m.markerView(m.pathOf(f)))
 		if isSymlink {
 			fileName += " → " + symlinkPath
 		}
@@ -431,17 +779,24 @@ func (m Model) View() string {
 			s.WriteString(" " + m.Styles.Permission.Render(info.Mode().String()))
 		}
 		if m.ShowSize {
-			s.WriteString(m.Styles.FileSize.Render(size))
+			s.WriteString(m.Styles.FileSize.Width(sizeWidth).Render(size))
+		}
+		if m.ShowModTime {
+			s.WriteString(" " + m.Styles.ModTime.Render(padRight(m.modTimeOf(info), modTimeWidth)))
+		}
+		if m.ShowOwner {
+			s.WriteString(" " + m.Styles.Owner.Render(padRight(ownerOf(info), ownerWidth)))
 		}
-		s.WriteString(" " + fileName)
+		s.WriteString(" " + m.Styles.TreeGuide.Render(guideOf(f)) + fileName)
 		s.WriteRune('\n')
 	}
 
-	for i := lipgloss.Height(s.String()); i <= m.Height; i++ {
+	for i := lipgloss.Height(s.String()); i <= height; i++ {
 		s.WriteRune('\n')
 	}
+	s.WriteString(status)
 
-	return s.String()
+	return m.withPreview(s.String(), height)
 }
 
 // DidSelectFile returns whether a user has selected a file (on this msg).
@@ -465,7 +820,10 @@ func (m Model) DidSelectDisabledFile(msg tea.Msg) (bool, string) {
 }
 
 func (m Model) didSelectFile(msg tea.Msg) (bool, string) {
-	if len(m.files) == 0 {
+	if m.pathEntered {
+		return true, m.Path
+	}
+	if len(m.files) == 0 || m.SaveMode || m.keyConsumed || m.MultiSelect && len(m.marked) > 0 {
 		return false, ""
 	}
 	switch msg := msg.(type) {
@@ -486,8 +844,8 @@ func (m Model) didSelectFile(msg tea.Msg) (bool, string) {
 		isDir := f.IsDir()
 
 		if isSymlink {
-			symlinkPath, _ := filepath.EvalSymlinks(filepath.Join(m.CurrentDirectory, f.Name()))
-			info, err := os.Stat(symlinkPath)
+			symlinkPath, _ := m.fileSystem().EvalSymlinks(m.pathOf(f))
+			info, err := m.fileSystem().Stat(symlinkPath)
 			if err != nil {
 				break
 			}
@@ -508,6 +866,18 @@ func (m Model) didSelectFile(msg tea.Msg) (bool, string) {
 	return false, ""
 }
 
+// statusView renders the line below the files, showing the filter and the
+// number of marked files.
+func (m Model) statusView() string {
+	var parts []string
+	for _, s := range []string{m.pathInputView(), m.saveView(), m.filterView(), m.jumpView(), m.markedCountView()} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 func (m Model) canSelect(file string) bool {
 	if len(m.AllowedTypes) <= 0 {
 		return true
@@ -0,0 +1,850 @@
+package filepicker
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// newTestModel returns a file picker browsing fsys, with its current
+// directory loaded.
+func newTestModel(t *testing.T, fsys fstest.MapFS) Model {
+	t.Helper()
+	m := New()
+	m.FS = FromFS(fsys)
+	m.AutoHeight = false
+	m.SetHeight(5)
+
+	// Blinking cursors would keep run from returning.
+	m.PathInput.Cursor.SetMode(cursor.CursorStatic)
+	m.NameInput.Cursor.SetMode(cursor.CursorStatic)
+	return run(t, m, m.Init())
+}
+
+// run updates the model with the files, completions and previews loaded by
+// cmd, and by the resulting commands, until there are none left. Errors fail
+// the test, and other messages are dropped.
+func run(t *testing.T, m Model, cmd tea.Cmd) Model {
+	t.Helper()
+	for pending := []tea.Cmd{cmd}; len(pending) > 0; {
+		cmd, pending = pending[0], pending[1:]
+		if cmd == nil {
+			continue
+		}
+		switch msg := cmd().(type) {
+		case tea.BatchMsg:
+			pending = append(pending, msg...)
+		case errorMsg:
+			t.Fatalf("unexpected error: %v", msg.err)
+		case readDirMsg, completionMsg, previewMsg:
+			m, cmd = m.Update(msg)
+			pending = append(pending, cmd)
+		}
+	}
+	return m
+}
+
+// press sends the given keys to the model, one key press each. Keys are
+// given as their names, such as "enter", or as runes.
+func press(t *testing.T, m Model, keys ...string) Model {
+	t.Helper()
+	for _, k := range keys {
+		var cmd tea.Cmd
+		m, cmd = m.Update(keyMsg(k))
+		m = run(t, m, cmd)
+	}
+	return m
+}
+
+func keyMsg(k string) tea.KeyMsg {
+	switch k {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "left":
+		return tea.KeyMsg{Type: tea.KeyLeft}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// names returns the names of the listed files.
+func names(m Model) []string {
+	names := make([]string, len(m.files))
+	for i, f := range m.files {
+		names[i] = f.Name()
+	}
+	return names
+}
+
+// selectedName returns the name of the file under the cursor.
+func selectedName(m Model) string {
+	if m.selected < 0 || m.selected >= len(m.files) {
+		return ""
+	}
+	return m.files[m.selected].Name()
+}
+
+var testFS = fstest.MapFS{
+	"apple.txt":      {Data: []byte("apple")},
+	"banana.txt":     {Data: []byte("banana")},
+	"blueberry.md":   {Data: []byte("blueberry")},
+	"cherry.txt":     {Data: []byte("cherry")},
+	"citrus/lime.go": {Data: []byte("package lime")},
+}
+
+func TestFilter(t *testing.T) {
+	m := newTestModel(t, testFS)
+
+	m = press(t, m, "/", "b", "r")
+	if !m.Filtering() {
+		t.Fatal("expected to be filtering")
+	}
+	if got, want := names(m), []string{"blueberry.md"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	m = press(t, m, "backspace")
+	if got, want := names(m), []string{"banana.txt", "blueberry.md"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// Enter applies the filter rather than selecting the file.
+	m = press(t, m, "down")
+	if ok, _ := m.DidSelectFile(keyMsg("enter")); ok {
+		t.Fatal("expected no selection while filtering")
+	}
+	m = press(t, m, "enter")
+	if m.Filtering() || m.FilterValue() != "b" || selectedName(m) != "blueberry.md" {
+		t.Fatalf("expected filter %q to be applied on blueberry.md, got %q on %s",
+			"b", m.FilterValue(), selectedName(m))
+	}
+
+	// Escape clears the applied filter, keeping the cursor on its file.
+	m = press(t, m, "esc")
+	if m.FilterValue() != "" || len(m.files) != len(m.allFiles) {
+		t.Fatalf("expected filter to be cleared, got %q with %v", m.FilterValue(), names(m))
+	}
+	if got, want := selectedName(m), "blueberry.md"; got != want {
+		t.Fatalf("expected %s to stay selected, got %s", want, got)
+	}
+}
+
+func TestPrefixFilter(t *testing.T) {
+	m := newTestModel(t, testFS)
+	m.PrefixFilter = true
+
+	m.SetFilter("C")
+	if got, want := names(m), []string{"citrus", "cherry.txt"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestStatusLineWithinHeight(t *testing.T) {
+	m := newTestModel(t, testFS)
+	m = press(t, m, "G")
+
+	if got := lipgloss.Height(strings.TrimSuffix(m.View(), "\n")); got != m.Height {
+		t.Fatalf("expected %d lines, got %d", m.Height, got)
+	}
+
+	m = press(t, m, "/")
+	view := m.View()
+	if got := lipgloss.Height(view); got != m.Height {
+		t.Fatalf("expected %d lines with the filter shown, got %d:\n%s", m.Height, got, view)
+	}
+	if !strings.Contains(view, selectedName(m)) {
+		t.Fatalf("expected the selected file to stay visible, got:\n%s", view)
+	}
+}
+
+func TestQuickJump(t *testing.T) {
+	m := newTestModel(t, testFS)
+
+	m = press(t, m, "c")
+	if got, want := selectedName(m), "cherry.txt"; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	m = press(t, m, "C")
+	if got, want := selectedName(m), "citrus"; got != want {
+		t.Fatalf("expected the jump to wrap around to %s, got %s", want, got)
+	}
+
+	// Letters bound to keys keep their binding.
+	m = press(t, m, "j")
+	if got, want := selectedName(m), "apple.txt"; got != want {
+		t.Fatalf("expected j to move down to %s, got %s", want, got)
+	}
+
+	m.QuickJump = false
+	if m = press(t, m, "c"); selectedName(m) != "apple.txt" {
+		t.Fatalf("expected the cursor not to move without quick-jump, got %s", selectedName(m))
+	}
+}
+
+func TestQuickJump_JumpKey(t *testing.T) {
+	m := newTestModel(t, fstest.MapFS{
+		"apple.txt": {},
+		"jam.txt":   {},
+		"kiwi.txt":  {},
+	})
+
+	// Bound letters are jumped to after the Jump key.
+	m = press(t, m, "'")
+	if !strings.Contains(m.View(), "jump to") {
+		t.Fatalf("expected the jump prompt, got:\n%s", m.View())
+	}
+	m = press(t, m, "k")
+	if got, want := selectedName(m), "kiwi.txt"; got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	if strings.Contains(m.View(), "jump to") {
+		t.Fatalf("expected the jump prompt to be gone, got:\n%s", m.View())
+	}
+	if m = press(t, m, "k"); selectedName(m) != "jam.txt" {
+		t.Fatalf("expected k to move up to jam.txt, got %s", selectedName(m))
+	}
+
+	// Other keys cancel the jump without taking effect.
+	m = press(t, m, "'", "esc")
+	if m.CurrentDirectory != "." || selectedName(m) != "jam.txt" || strings.Contains(m.View(), "jump to") {
+		t.Fatalf("expected the jump to be canceled, got %s in %s", selectedName(m), m.CurrentDirectory)
+	}
+}
+
+func TestMarks(t *testing.T) {
+	m := newTestModel(t, testFS)
+	m.MultiSelect = true
+	m.AllowedTypes = []string{".txt", ".go"}
+
+	// Files of other types can't be marked.
+	m = press(t, m, "j", "x", "j", " ", "j", "x")
+	if got, want := m.MarkedPaths(), []string{"apple.txt", "banana.txt"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v to be marked, got %v", want, got)
+	}
+
+	// Marks are kept across directories.
+	m = press(t, m, "g", "l", "x", "h")
+	if got, want := m.MarkedPaths(), []string{"apple.txt", "banana.txt", "citrus/lime.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v to be marked, got %v", want, got)
+	}
+	if !strings.Contains(m.View(), "3 selected") {
+		t.Fatalf("expected the marked count to be shown, got:\n%s", m.View())
+	}
+
+	// Toggling a marked file unmarks it.
+	m = press(t, m, "j", "x")
+	if m.IsMarked("apple.txt") {
+		t.Fatal("expected apple.txt to be unmarked")
+	}
+
+	msg := keyMsg("enter")
+	m, _ = m.Update(msg)
+	if ok, _ := m.DidSelectFile(msg); ok {
+		t.Fatal("expected no single file to be selected while files are marked")
+	}
+	ok, paths := m.DidSelectFiles(msg)
+	if want := []string{"banana.txt", "citrus/lime.go"}; !ok || !reflect.DeepEqual(paths, want) {
+		t.Fatalf("expected %v to be selected, got %v (%t)", want, paths, ok)
+	}
+
+	m.ClearMarks()
+	m, _ = m.Update(msg)
+	if ok, paths := m.DidSelectFiles(msg); !ok || !reflect.DeepEqual(paths, []string{"apple.txt"}) {
+		t.Fatalf("expected the file under the cursor to be selected without marks, got %v (%t)", paths, ok)
+	}
+}
+
+func TestMarks_MaxSelected(t *testing.T) {
+	m := newTestModel(t, testFS)
+	m.MultiSelect = true
+	m.MaxSelected = 2
+
+	m = press(t, m, "j", "x", "j", "x", "j", "x")
+	if got, want := m.MarkedPaths(), []string{"apple.txt", "banana.txt"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v to be marked, got %v", want, got)
+	}
+	if !strings.Contains(m.View(), "2/2 selected") {
+		t.Fatalf("expected the marked count to show the limit, got:\n%s", m.View())
+	}
+
+	// Directories are only marked if they may be selected.
+	m.ClearMarks()
+	m = press(t, m, "g", "x")
+	if m.IsMarked("citrus") {
+		t.Fatal("expected the directory not to be marked")
+	}
+	m.DirAllowed = true
+	if m = press(t, m, "x"); !m.IsMarked("citrus") {
+		t.Fatal("expected the directory to be marked")
+	}
+}
+
+func TestFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		".hidden":         {Data: []byte("secret")},
+		"docs/readme.txt": {Data: []byte("hello")},
+		"docs/.draft.txt": {Data: []byte("draft")},
+		"main.go":         {Data: []byte("package main")},
+	}
+
+	m := newTestModel(t, fsys)
+	if got, want := names(m), []string{"docs", "main.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	m.ShowHidden = true
+	m = run(t, m, m.Init())
+	if got, want := names(m), []string{"docs", ".hidden", "main.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected hidden files to be shown, got %v", got)
+	}
+
+	// Directories are opened relative to the root of the filesystem.
+	m.ShowHidden = false
+	m = press(t, m, "l")
+	if got, want := m.CurrentDirectory, "docs"; got != want {
+		t.Fatalf("expected to be in %s, got %s", want, got)
+	}
+	if got, want := names(m), []string{"readme.txt"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	msg := keyMsg("enter")
+	m, _ = m.Update(msg)
+	if ok, path := m.DidSelectFile(msg); !ok || path != "docs/readme.txt" {
+		t.Fatalf("expected docs/readme.txt to be selected, got %q (%t)", path, ok)
+	}
+
+	m = press(t, m, "h")
+	if got, want := m.CurrentDirectory, "."; got != want {
+		t.Fatalf("expected to be back in %s, got %s", want, got)
+	}
+}
+
+func TestCleanFSPath(t *testing.T) {
+	for in, want := range map[string]string{
+		"":            ".",
+		".":           ".",
+		"/":           ".",
+		"docs/":       "docs",
+		"./docs/../a": "a",
+		"/docs/a.txt": "docs/a.txt",
+	} {
+		if got := cleanFSPath(in); got != want {
+			t.Errorf("cleanFSPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+var treeFS = fstest.MapFS{
+	"src/lib/util.go": {Data: []byte("package lib")},
+	"src/main.go":     {Data: []byte("package main")},
+	"readme.txt":      {Data: []byte("hello")},
+}
+
+func TestTreeView(t *testing.T) {
+	m := New()
+	m.FS = FromFS(treeFS)
+	m.CurrentDirectory = "src"
+	m.AutoHeight = false
+	m.TreeView = true
+	m.SetHeight(5)
+	m = run(t, m, m.Init())
+
+	m = press(t, m, "l")
+	if got, want := names(m), []string{"lib", "util.go", "main.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if !m.IsExpanded("src/lib") {
+		t.Fatal("expected src/lib to be expanded")
+	}
+	if !strings.Contains(m.View(), "└─ util.go") {
+		t.Fatalf("expected tree guides, got:\n%s", m.View())
+	}
+
+	// Collapse moves to the parent of nested files, then collapses it.
+	m = press(t, m, "j", "h")
+	if got, want := selectedName(m), "lib"; got != want {
+		t.Fatalf("expected the cursor on %s, got %s", want, got)
+	}
+	m = press(t, m, "h")
+	if got, want := names(m), []string{"lib", "main.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	// On collapsed files of the current directory, it goes back.
+	m = press(t, m, "h")
+	if got, want := m.CurrentDirectory, "."; got != want {
+		t.Fatalf("expected to go back to %s, got %s", want, got)
+	}
+	if got, want := names(m), []string{"src", "readme.txt"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestTreeView_Toggle(t *testing.T) {
+	m := New()
+	m.FS = FromFS(treeFS)
+	m.CurrentDirectory = "src"
+	m.AutoHeight = false
+	m.SetHeight(5)
+	m = run(t, m, m.Init())
+
+	// Turning the tree on after the files were loaded lays them out again.
+	m.TreeView = true
+	m = press(t, m, "l")
+	if got, want := names(m), []string{"lib", "util.go", "main.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if !strings.Contains(m.View(), "└─ util.go") {
+		t.Fatalf("expected tree guides, got:\n%s", m.View())
+	}
+
+	m.TreeView = false
+	m = press(t, m, "j")
+	if got, want := names(m), []string{"lib", "main.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if strings.Contains(m.View(), "─") {
+		t.Fatalf("expected no tree guides, got:\n%s", m.View())
+	}
+}
+
+func TestPreview(t *testing.T) {
+	fsys := fstest.MapFS{
+		"binary.dat": {Data: []byte{'a', 0, 'b'}},
+		"notes.txt":  {Data: []byte("one\ntwo\tthree\nfour")},
+		"sub/a.txt":  {Data: []byte("a")},
+		"sub/b":      {Mode: fs.ModeDir},
+	}
+	m := New()
+	m.FS = FromFS(fsys)
+	m.AutoHeight = false
+	m.ShowPreview = true
+	m.PreviewLines = 2
+	m.SetHeight(6)
+	m = run(t, m, m.Init())
+
+	if got, want := m.preview.content, "a.txt\nb/"; got != want {
+		t.Fatalf("expected the directory preview %q, got %q", want, got)
+	}
+
+	// Previews are loaded in the background.
+	m, cmd := m.Update(keyMsg("j"))
+	if !m.preview.loading || !strings.Contains(m.View(), "Loading…") {
+		t.Fatalf("expected the preview to be loading, got:\n%s", m.View())
+	}
+	m = run(t, m, cmd)
+	if got, want := m.preview.content, "(binary file)"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	m = press(t, m, "j")
+	if got, want := m.preview.content, "one\ntwo    three"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if !strings.Contains(m.View(), "two    three") {
+		t.Fatalf("expected the preview to be shown, got:\n%s", m.View())
+	}
+
+	// Changed files are previewed again once their directory is reloaded.
+	fsys["notes.txt"] = &fstest.MapFile{Data: []byte("changed")}
+	m = run(t, m, m.Init())
+	if got, want := m.preview.content, "changed"; got != want {
+		t.Fatalf("expected the preview to be reloaded as %q, got %q", want, got)
+	}
+
+	// The pane fits the width including its frame, and the height of the
+	// files.
+	view := m.previewView(m.Height)
+	if got, want := lipgloss.Width(view), m.PreviewWidth; got != want {
+		t.Fatalf("expected a width of %d, got %d", want, got)
+	}
+	if got, want := lipgloss.Height(view), m.Height; got != want {
+		t.Fatalf("expected a height of %d, got %d", want, got)
+	}
+	m.SetFilter("notes")
+	m = press(t, m, "/")
+	if got, want := lipgloss.Height(m.View()), m.Height; got != want {
+		t.Fatalf("expected a height of %d with the status line shown, got %d:\n%s", want, got, m.View())
+	}
+}
+
+func TestIsBinary(t *testing.T) {
+	for _, tt := range []struct {
+		data []byte
+		want bool
+	}{
+		{[]byte("plain text"), false},
+		{[]byte("héllo"), false},
+		{[]byte("héllo")[:2], false}, // cut off in the middle of a rune
+		{[]byte("nul\x00byte"), true},
+		{[]byte("bad\xffutf8 text"), true},
+		{nil, false},
+	} {
+		if got := isBinary(tt.data); got != tt.want {
+			t.Errorf("isBinary(%q) = %t, want %t", tt.data, got, tt.want)
+		}
+	}
+}
+
+// countingEntry counts the calls to Info.
+type countingEntry struct {
+	fs.DirEntry
+	calls *int
+}
+
+func (e countingEntry) Info() (fs.FileInfo, error) {
+	*e.calls++
+	return e.DirEntry.Info() //nolint:wrapcheck
+}
+
+func TestSortFiles(t *testing.T) {
+	now := time.Now()
+	fsys := fstest.MapFS{
+		"b10.txt": {Data: []byte("12345"), ModTime: now.Add(-time.Hour)},
+		"b9.md":   {Data: []byte("1"), ModTime: now},
+		"a.go":    {Data: []byte("123"), ModTime: now.Add(-2 * time.Hour)},
+		"z/x":     {},
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		order      SortOrder
+		descending bool
+		want       []string
+	}{
+		{SortByName, false, []string{"z", "a.go", "b10.txt", "b9.md"}},
+		{SortByName, true, []string{"z", "b9.md", "b10.txt", "a.go"}},
+		{SortByNatural, false, []string{"z", "a.go", "b9.md", "b10.txt"}},
+		{SortBySize, false, []string{"z", "b9.md", "a.go", "b10.txt"}},
+		{SortBySize, true, []string{"z", "b10.txt", "a.go", "b9.md"}},
+		{SortByModTime, false, []string{"z", "a.go", "b10.txt", "b9.md"}},
+		{SortByExtension, false, []string{"z", "a.go", "b9.md", "b10.txt"}},
+	} {
+		t.Run(tt.order.String(), func(t *testing.T) {
+			var calls int
+			files := make([]os.DirEntry, len(entries))
+			for i, e := range entries {
+				files[i] = countingEntry{e, &calls}
+			}
+
+			sortFiles(files, tt.order, tt.descending)
+			got := make([]string, len(files))
+			for i, f := range files {
+				got[i] = f.Name()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if calls > len(files) {
+				t.Errorf("expected at most one Info call per file, got %d", calls)
+			}
+		})
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want bool
+	}{
+		{"file2", "file10", true},
+		{"file10", "file2", false},
+		{"file02", "file2", true}, // equal numbers compare lexically
+		{"file2", "file02", false},
+		{"a1b2", "a1b10", true},
+		{"abc", "abd", true},
+		{"abc", "abc1", true},
+		{"abc", "abc", false},
+		{"10", "9a", false},
+	} {
+		if got := naturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalLess(%q, %q) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSortKeys(t *testing.T) {
+	m := newTestModel(t, testFS)
+
+	m = press(t, m, "G", "s", "s")
+	if got, want := m.SortOrder, SortBySize; got != want {
+		t.Fatalf("expected to sort by %s, got %s", want, got)
+	}
+	if got, want := names(m), []string{"citrus", "apple.txt", "banana.txt", "cherry.txt", "blueberry.md"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got, want := selectedName(m), "cherry.txt"; got != want {
+		t.Fatalf("expected the cursor to stay on %s, got %s", want, got)
+	}
+
+	// Bound letters aren't used for quick-jump.
+	m = press(t, m, "S")
+	if !m.SortDescending || selectedName(m) != "cherry.txt" {
+		t.Fatalf("expected S to reverse the order, got %v on %s", names(m), selectedName(m))
+	}
+}
+
+// typePath enters the path input and types the given text into it.
+func typePath(t *testing.T, m Model, text string) Model {
+	t.Helper()
+	m = press(t, m, ":")
+	for _, r := range text {
+		m = press(t, m, string(r))
+	}
+	return m
+}
+
+func TestPathInput_Completion(t *testing.T) {
+	m := newTestModel(t, testFS)
+
+	m = typePath(t, m, "ci")
+	if !m.EnteringPath() {
+		t.Fatal("expected to be entering a path")
+	}
+	if got, want := m.PathInput.MatchedSuggestions(), []string{"./citrus/"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected suggestions %v, got %v", want, got)
+	}
+
+	// Accepting a directory completes the files in it.
+	m = press(t, m, "tab")
+	if got, want := m.PathInput.Value(), "./citrus/"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if got, want := m.PathInput.AvailableSuggestions(), []string{"./citrus/lime.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected suggestions %v, got %v", want, got)
+	}
+
+	m = press(t, m, "enter")
+	if m.EnteringPath() || m.CurrentDirectory != "citrus" {
+		t.Fatalf("expected to open citrus, got %s", m.CurrentDirectory)
+	}
+	if got, want := names(m), []string{"lime.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestPathInput_Subdirectory(t *testing.T) {
+	m := newTestModel(t, testFS)
+	m = press(t, m, "l")
+	if m.CurrentDirectory != "citrus" {
+		t.Fatalf("expected to open citrus, got %s", m.CurrentDirectory)
+	}
+
+	// Paths are typed relative to the current directory.
+	m = typePath(t, m, "l")
+	if got, want := m.PathInput.MatchedSuggestions(), []string{"./lime.go"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected suggestions %v, got %v", want, got)
+	}
+	m = press(t, m, "tab")
+	msg := keyMsg("enter")
+	m, _ = m.Update(msg)
+	if ok, path := m.DidSelectFile(msg); !ok || path != "citrus/lime.go" {
+		t.Fatalf("expected citrus/lime.go to be selected, got %q (%t), error %v", path, ok, m.pathErr)
+	}
+
+	// Absolute directories are filled in as they are.
+	dir := t.TempDir()
+	m = New()
+	m.CurrentDirectory = dir
+	m.PathInput.Cursor.SetMode(cursor.CursorStatic)
+	m = press(t, m, ":")
+	if got, want := m.PathInput.Value(), dir+string(filepath.Separator); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestPathInput_Validation(t *testing.T) {
+	m := newTestModel(t, testFS)
+	m.AllowedTypes = []string{".txt"}
+
+	m = press(t, typePath(t, m, "missing.txt"), "enter")
+	if !m.EnteringPath() || m.pathErr == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+
+	m = press(t, m, "esc")
+	m = press(t, typePath(t, m, "blueberry.md"), "enter")
+	if !m.EnteringPath() || m.pathErr == nil || !strings.Contains(m.View(), "blueberry.md can't be selected") {
+		t.Fatalf("expected an error for a disallowed file, got:\n%s", m.View())
+	}
+
+	// Directories are only selected without a trailing separator, and if
+	// they may be.
+	m.AllowedTypes, m.DirAllowed = nil, true
+	m = press(t, m, "esc")
+	m = typePath(t, m, "citrus")
+	msg := keyMsg("enter")
+	m, _ = m.Update(msg)
+	if ok, path := m.DidSelectFile(msg); !ok || path != "citrus" {
+		t.Fatalf("expected citrus to be selected, got %q (%t)", path, ok)
+	}
+	if m.CurrentDirectory != "." {
+		t.Fatalf("expected to stay in ., got %s", m.CurrentDirectory)
+	}
+
+	m = typePath(t, m, "apple.txt")
+	m, _ = m.Update(msg)
+	if ok, path := m.DidSelectFile(msg); !ok || path != "apple.txt" {
+		t.Fatalf("expected apple.txt to be selected, got %q (%t)", path, ok)
+	}
+
+	// The selection only fires on the key press that accepted the path.
+	next := keyMsg("j")
+	m, _ = m.Update(next)
+	if ok, _ := m.DidSelectFile(next); ok {
+		t.Fatal("expected no selection on the next key press")
+	}
+}
+
+// statErrFS fails to stat the files in it with err.
+type statErrFS struct {
+	FS
+	err error
+}
+
+func (f statErrFS) Stat(string) (fs.FileInfo, error) {
+	return nil, f.err
+}
+
+// saveAs types the given name to save as, and accepts it.
+func saveAs(t *testing.T, m Model, name string) (Model, tea.KeyMsg) {
+	t.Helper()
+	m.NameInput.SetValue(name)
+	m = press(t, m, "tab")
+	msg := keyMsg("enter")
+	m, cmd := m.Update(msg)
+	return run(t, m, cmd), msg
+}
+
+func TestSaveMode(t *testing.T) {
+	m := newTestModel(t, testFS)
+	m.SaveMode = true
+	m.AllowedTypes = []string{".txt"}
+
+	m, msg := saveAs(t, m, "report")
+	if ok, path := m.DidChooseSavePath(msg); !ok || path != "report.txt" {
+		t.Fatalf("expected report.txt to be chosen, got %q (%t)", path, ok)
+	}
+	if ok, _ := m.DidSelectFile(msg); ok {
+		t.Fatal("expected no file to be selected in save mode")
+	}
+
+	// Existing files need to be confirmed.
+	m, msg = saveAs(t, m, "apple")
+	if ok, _ := m.DidChooseSavePath(msg); ok || !strings.Contains(m.View(), "apple.txt already exists") {
+		t.Fatalf("expected to confirm overwriting apple.txt, got:\n%s", m.View())
+	}
+	msg = keyMsg("n")
+	if m, _ = m.Update(msg); m.overwritePath != "" {
+		t.Fatal("expected overwriting to be canceled")
+	}
+	m, _ = saveAs(t, m, "apple")
+	msg = keyMsg("y")
+	m, _ = m.Update(msg)
+	if ok, path := m.DidChooseSavePath(msg); !ok || path != "apple.txt" {
+		t.Fatalf("expected apple.txt to be chosen, got %q (%t)", path, ok)
+	}
+
+	// Folders and names of other directories are refused.
+	m.AllowedTypes = nil
+	for _, name := range []string{"citrus", "..", "citrus/x.txt", "../x.txt"} {
+		m, msg = saveAs(t, m, name)
+		if ok, _ := m.DidChooseSavePath(msg); ok || m.saveErr == nil || !m.EditingName() {
+			t.Errorf("expected %q to be refused", name)
+		}
+		m = press(t, m, "esc")
+	}
+
+	// Only missing files are saved to when they can't be checked.
+	m.FS = statErrFS{m.FS, fs.ErrPermission}
+	m, msg = saveAs(t, m, "locked.txt")
+	if ok, _ := m.DidChooseSavePath(msg); ok || !errors.Is(m.saveErr, fs.ErrPermission) {
+		t.Fatalf("expected a permission error, got %v", m.saveErr)
+	}
+}
+
+func TestSaveMode_PathInput(t *testing.T) {
+	m := newTestModel(t, testFS)
+	m.SaveMode = true
+	m.AllowedTypes = []string{".txt"}
+
+	m = typePath(t, m, "citrus/notes")
+	msg := keyMsg("enter")
+	m, _ = m.Update(msg)
+	if ok, path := m.DidChooseSavePath(msg); !ok || path != "citrus/notes.txt" {
+		t.Fatalf("expected citrus/notes.txt to be chosen, got %q (%t)", path, ok)
+	}
+	if ok, _ := m.DidSelectFile(msg); ok {
+		t.Fatal("expected no file to be selected in save mode")
+	}
+	if got, want := m.NameInput.Value(), "notes.txt"; got != want {
+		t.Fatalf("expected the name %q, got %q", want, got)
+	}
+
+	m = press(t, typePath(t, m, "missing/notes.txt"), "enter")
+	if !m.EnteringPath() || !errors.Is(m.pathErr, fs.ErrNotExist) {
+		t.Fatalf("expected an error for a missing directory, got %v", m.pathErr)
+	}
+
+	m = press(t, m, "esc")
+	m = press(t, typePath(t, m, "apple.txt"), "enter")
+	if m.overwritePath != "apple.txt" {
+		t.Fatal("expected to confirm overwriting apple.txt")
+	}
+
+	m = press(t, m, "n")
+	m = press(t, typePath(t, m, "citrus"), "enter")
+	if m.CurrentDirectory != "citrus" {
+		t.Fatalf("expected to open citrus, got %s", m.CurrentDirectory)
+	}
+}
+
+func TestSaveMode_NewFolder(t *testing.T) {
+	m := New()
+	m.CurrentDirectory = t.TempDir()
+	m.AutoHeight = false
+	m.SaveMode = true
+	m.SetHeight(5)
+	m.NameInput.Cursor.SetMode(cursor.CursorStatic)
+	m = run(t, m, m.Init())
+
+	m = press(t, m, "N", "d", "o", "c", "s", "enter")
+	if m.EditingName() || !reflect.DeepEqual(names(m), []string{"docs"}) {
+		t.Fatalf("expected the docs folder to be created, got %v", names(m))
+	}
+
+	// Folders can't be created in read-only filesystems.
+	m = newTestModel(t, testFS)
+	m.SaveMode = true
+	m = press(t, m, "N", "x", "enter")
+	if !m.EditingName() || !errors.Is(m.saveErr, errNoMkdir) {
+		t.Fatalf("expected %v, got %v", errNoMkdir, m.saveErr)
+	}
+}
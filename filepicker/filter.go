@@ -0,0 +1,167 @@
+package filepicker
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+)
+
+// Filtering returns whether the user is typing a filter.
+func (m Model) Filtering() bool {
+	return m.filtering
+}
+
+// FilterValue returns the current filter.
+func (m Model) FilterValue() string {
+	return m.filter
+}
+
+// SetFilter narrows the files in the current directory down to those
+// matching the given filter. An empty filter shows all files.
+func (m *Model) SetFilter(filter string) {
+	m.filter = filter
+	m.applyFilter()
+}
+
+// updateFiltering handles key presses while the user is typing a filter.
+func (m *Model) updateFiltering(msg tea.KeyMsg) {
+	switch {
+	case key.Matches(msg, m.KeyMap.ClearFilter):
+		m.filtering = false
+		m.SetFilter("")
+	case key.Matches(msg, m.KeyMap.AcceptFilter):
+		m.filtering = false
+	case msg.Type == tea.KeyUp:
+		m.selected = max(0, m.selected-1)
+		m.keepSelectedVisible()
+	case msg.Type == tea.KeyDown:
+		m.selected = max(0, min(m.selected+1, len(m.files)-1))
+		m.keepSelectedVisible()
+	case msg.Type == tea.KeyBackspace:
+		if m.filter != "" {
+			_, size := utf8.DecodeLastRuneInString(m.filter)
+			m.SetFilter(m.filter[:len(m.filter)-size])
+		}
+	case msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace:
+		m.SetFilter(m.filter + string(msg.Runes))
+	}
+}
+
+// applyFilter narrows the files down to those matching the filter, keeping
+// the selected file selected if it still matches.
+func (m *Model) applyFilter() {
+	var current string
+	if m.selected >= 0 && m.selected < len(m.files) {
+		current = m.pathOf(m.files[m.selected])
+	}
+
+	m.files, m.matches = m.allFiles, nil
+	if m.filter != "" {
+		m.files, m.matches = nil, nil
+		for i, f := range m.allFiles {
+			if matches, ok := m.match(f.Name()); ok {
+				m.files = append(m.files, m.allFiles[i])
+				m.matches = append(m.matches, matches)
+			}
+		}
+	}
+
+	m.selected = 0
+	for i, f := range m.files {
+		if m.pathOf(f) == current {
+			m.selected = i
+			break
+		}
+	}
+	m.min, m.max = 0, m.Height-1
+	m.keepSelectedVisible()
+}
+
+// match returns whether the file name matches the filter and the rune
+// indices of the matched characters.
+func (m Model) match(name string) ([]int, bool) {
+	if m.PrefixFilter {
+		if !strings.HasPrefix(strings.ToLower(name), strings.ToLower(m.filter)) {
+			return nil, false
+		}
+		matches := make([]int, utf8.RuneCountInString(m.filter))
+		for i := range matches {
+			matches[i] = i
+		}
+		return matches, true
+	}
+
+	found := fuzzy.FindNoSort(m.filter, []string{name})
+	if len(found) == 0 {
+		return nil, false
+	}
+	matches := make([]int, len(found[0].MatchedIndexes))
+	for i, b := range found[0].MatchedIndexes {
+		matches[i] = utf8.RuneCountInString(name[:b])
+	}
+	return matches, true
+}
+
+// quickJump moves the cursor to the next file starting with the given
+// letter, wrapping around. It returns whether a file was found.
+func (m *Model) quickJump(r rune) bool {
+	r = unicode.ToLower(r)
+	for i := 1; i <= len(m.files); i++ {
+		j := (m.selected + i) % len(m.files)
+		first, _ := utf8.DecodeRuneInString(m.files[j].Name())
+		if unicode.ToLower(first) == r {
+			m.selected = j
+			m.keepSelectedVisible()
+			return true
+		}
+	}
+	return false
+}
+
+// keepSelectedVisible scrolls as little as possible to bring the selected
+// file into view.
+func (m *Model) keepSelectedVisible() {
+	if m.selected < m.min {
+		m.min = m.selected
+		m.max = m.min + m.Height - 1
+	}
+	if m.selected > m.max {
+		m.max = m.selected
+		m.min = m.max - m.Height + 1
+	}
+}
+
+// filterView renders the filter, if any.
+func (m Model) filterView() string {
+	if !m.filtering && m.filter == "" {
+		return ""
+	}
+	s := m.Styles.FilterPrompt.Render("/") + m.filter
+	if m.filtering {
+		s += m.Styles.Cursor.Render("█")
+	}
+	return s
+}
+
+// jumpView renders the prompt shown after the Jump key, until a letter is
+// typed.
+func (m Model) jumpView() string {
+	if !m.jumping {
+		return ""
+	}
+	return m.Styles.FilterPrompt.Render("jump to ") + m.Styles.Cursor.Render("█")
+}
+
+// renderName renders a file name, highlighting the characters matched by
+// the filter.
+func (m Model) renderName(i int, name string, style lipgloss.Style) string {
+	if i >= len(m.matches) || len(m.matches[i]) == 0 {
+		return style.Render(name)
+	}
+	return lipgloss.StyleRunes(name, m.matches[i], style.Inherit(m.Styles.FilterMatch), style)
+}
@@ -0,0 +1,103 @@
+package filepicker
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// FS is a filesystem the file picker can browse. Paths are given as they
+// appear in Model.CurrentDirectory.
+type FS interface {
+	fs.ReadDirFS
+	fs.StatFS
+
+	// EvalSymlinks returns the path after resolving any symbolic links.
+	EvalSymlinks(path string) (string, error)
+
+	// IsHidden reports whether the file at the given path is hidden, in which
+	// case it's only shown if Model.ShowHidden is set.
+	IsHidden(path string) (bool, error)
+}
+
+// OSFS is the operating system's filesystem, which is browsed by default.
+type OSFS struct{}
+
+// Open opens the named file for reading.
+func (OSFS) Open(name string) (fs.File, error) {
+	return os.Open(name) //nolint:wrapcheck
+}
+
+// ReadDir reads the named directory.
+func (OSFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name) //nolint:wrapcheck
+}
+
+// Stat returns a FileInfo describing the named file, following symbolic
+// links.
+func (OSFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name) //nolint:wrapcheck
+}
+
+// EvalSymlinks returns the path after resolving any symbolic links.
+func (OSFS) EvalSymlinks(path string) (string, error) {
+	return filepath.EvalSymlinks(path) //nolint:wrapcheck
+}
+
+// Mkdir creates a directory with the given permissions.
+func (OSFS) Mkdir(path string, perm fs.FileMode) error {
+	return os.Mkdir(path, perm) //nolint:wrapcheck
+}
+
+// FromFS returns an FS browsing fsys, such as an embed.FS, a zip.Reader or an
+// fstest.MapFS. Paths are relative to the root of fsys, so CurrentDirectory
+// should be "." or a directory below it. Symbolic links aren't resolved, and
+// files whose name starts with a dot are hidden.
+func FromFS(fsys fs.FS) FS {
+	return ioFS{fsys}
+}
+
+type ioFS struct {
+	fsys fs.FS
+}
+
+func (f ioFS) Open(name string) (fs.File, error) {
+	return f.fsys.Open(cleanFSPath(name)) //nolint:wrapcheck
+}
+
+func (f ioFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(f.fsys, cleanFSPath(name)) //nolint:wrapcheck
+}
+
+func (f ioFS) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(f.fsys, cleanFSPath(name)) //nolint:wrapcheck
+}
+
+func (f ioFS) EvalSymlinks(name string) (string, error) {
+	return name, nil
+}
+
+func (f ioFS) IsHidden(name string) (bool, error) {
+	base := path.Base(cleanFSPath(name))
+	return base != "." && strings.HasPrefix(base, "."), nil
+}
+
+// cleanFSPath turns a path built with the filepath package into a valid
+// io/fs path.
+func cleanFSPath(name string) string {
+	name = strings.TrimPrefix(path.Clean(filepath.ToSlash(name)), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// fileSystem returns the filesystem being browsed.
+func (m Model) fileSystem() FS {
+	if m.FS == nil {
+		return OSFS{}
+	}
+	return m.FS
+}
@@ -3,9 +3,17 @@
 
 package filepicker
 
-import "strings"
+import (
+	"path/filepath"
+	"strings"
+)
 
 // IsHidden reports whether a file is hidden or not.
 func IsHidden(file string) (bool, error) {
 	return strings.HasPrefix(file, "."), nil
 }
+
+// IsHidden reports whether the file at the given path is hidden.
+func (OSFS) IsHidden(path string) (bool, error) {
+	return IsHidden(filepath.Base(path))
+}
@@ -19,3 +19,8 @@ func IsHidden(file string) (bool, error) {
 	}
 	return attributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0, nil
 }
+
+// IsHidden reports whether the file at the given path is hidden.
+func (OSFS) IsHidden(path string) (bool, error) {
+	return IsHidden(path)
+}
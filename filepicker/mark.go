@@ -0,0 +1,108 @@
+package filepicker
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// MarkedPaths returns the paths of the marked files in the order they were
+// marked.
+func (m Model) MarkedPaths() []string {
+	return slices.Clone(m.marked)
+}
+
+// IsMarked returns whether the file at the given path is marked.
+func (m Model) IsMarked(path string) bool {
+	return slices.Contains(m.marked, path)
+}
+
+// ClearMarks unmarks all files.
+func (m *Model) ClearMarks() {
+	m.marked = nil
+}
+
+// DidSelectFiles returns whether the user confirmed their selection (on this
+// msg) in multi-select mode, and the selected paths. These are the marked
+// files, or the file under the cursor if none are marked.
+func (m Model) DidSelectFiles(msg tea.Msg) (bool, []string) {
+	if !m.MultiSelect || len(m.marked) == 0 {
+		if ok, path := m.DidSelectFile(msg); ok {
+			return true, []string{path}
+		}
+		return false, nil
+	}
+	if msg, ok := msg.(tea.KeyMsg); !ok || m.keyConsumed || !key.Matches(msg, m.KeyMap.Select) {
+		return false, nil
+	}
+	return true, m.MarkedPaths()
+}
+
+// toggleMark marks or unmarks the file under the cursor. Files are only
+// marked if they may be selected, and while fewer than MaxSelected files are
+// marked.
+func (m *Model) toggleMark() {
+	if m.selected < 0 || m.selected >= len(m.files) {
+		return
+	}
+	f := m.files[m.selected]
+	path := m.pathOf(f)
+
+	if i := slices.Index(m.marked, path); i >= 0 {
+		m.marked = slices.Delete(m.marked, i, i+1)
+		return
+	}
+	if m.MaxSelected > 0 && len(m.marked) >= m.MaxSelected {
+		return
+	}
+	isDir := m.resolvesToDir(f)
+	if isDir && !m.DirAllowed || !isDir && (!m.FileAllowed || !m.canSelect(f.Name())) {
+		return
+	}
+	m.marked = append(m.marked, path)
+}
+
+// resolvesToDir returns whether the entry is a directory or a symlink to one.
+func (m Model) resolvesToDir(f os.DirEntry) bool {
+	if f.IsDir() {
+		return true
+	}
+	info, err := f.Info()
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+	fsys := m.fileSystem()
+	symlinkPath, err := fsys.EvalSymlinks(m.pathOf(f))
+	if err != nil {
+		return false
+	}
+	info, err = fsys.Stat(symlinkPath)
+	return err == nil && info.IsDir()
+}
+
+// markerView renders the marker column of a row in multi-select mode.
+func (m Model) markerView(path string) string {
+	if !m.MultiSelect {
+		return ""
+	}
+	if m.IsMarked(path) {
+		return m.Styles.Marked.Render(m.Marker)
+	}
+	return m.Styles.Marked.Render(strings.Repeat(" ", lipgloss.Width(m.Marker)))
+}
+
+// markedCountView renders the number of marked files, if any.
+func (m Model) markedCountView() string {
+	if !m.MultiSelect || len(m.marked) == 0 {
+		return ""
+	}
+	if m.MaxSelected > 0 {
+		return m.Styles.MarkedCount.Render(fmt.Sprintf("%d/%d selected", len(m.marked), m.MaxSelected))
+	}
+	return m.Styles.MarkedCount.Render(fmt.Sprintf("%d selected", len(m.marked)))
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package filepicker
+
+import "io/fs"
+
+// ownerOf returns the name of the user owning a file, which isn't supported
+// on this platform.
+func ownerOf(fs.FileInfo) string {
+	return ""
+}
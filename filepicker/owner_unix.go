@@ -0,0 +1,33 @@
+//go:build unix
+
+package filepicker
+
+import (
+	"io/fs"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// owners caches user names by user ID.
+var owners sync.Map
+
+// ownerOf returns the name of the user owning a file, if known.
+func ownerOf(info fs.FileInfo) string {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	uid := strconv.FormatUint(uint64(stat.Uid), 10)
+	if name, ok := owners.Load(uid); ok {
+		return name.(string) //nolint:forcetypeassert
+	}
+
+	name := uid
+	if u, err := user.LookupId(uid); err == nil {
+		name = u.Username
+	}
+	owners.Store(uid, name)
+	return name
+}
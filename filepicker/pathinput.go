@@ -0,0 +1,197 @@
+package filepicker
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// completionMsg carries the files of a directory, to complete paths typed
+// into the path input.
+type completionMsg struct {
+	id    int
+	dir   string
+	names []string
+}
+
+// EnteringPath returns whether the user is typing a path.
+func (m Model) EnteringPath() bool {
+	return m.enteringPath
+}
+
+// startPathInput shows the path input, filled in with the current
+// directory. Since relative paths are typed relative to the current
+// directory, a relative current directory is filled in as ".".
+func (m *Model) startPathInput() tea.Cmd {
+	m.enteringPath = true
+	m.pathErr = nil
+	m.completionDir = ""
+	value := "."
+	if filepath.IsAbs(m.CurrentDirectory) {
+		value = m.CurrentDirectory
+	}
+	if !strings.HasSuffix(value, string(filepath.Separator)) {
+		value += string(filepath.Separator)
+	}
+	m.PathInput.SetValue(value)
+	m.PathInput.CursorEnd()
+	return tea.Batch(m.PathInput.Focus(), m.completePath())
+}
+
+// stopPathInput hides the path input.
+func (m *Model) stopPathInput() {
+	m.enteringPath = false
+	m.pathErr = nil
+	m.PathInput.Blur()
+	m.PathInput.SetSuggestions(nil)
+}
+
+// updatePathInput handles key presses while the user is typing a path.
+func (m *Model) updatePathInput(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case key.Matches(msg, m.KeyMap.CancelPath):
+		m.stopPathInput()
+		return nil
+	case key.Matches(msg, m.KeyMap.AcceptPath):
+		return m.acceptPath()
+	}
+
+	m.pathErr = nil
+	var cmd tea.Cmd
+	m.PathInput, cmd = m.PathInput.Update(msg)
+	return tea.Batch(cmd, m.completePath())
+}
+
+// acceptPath goes to the typed path. Directories are opened, unless
+// DirAllowed is set and the path doesn't end with a separator, in which case
+// they're selected like files. In save mode, files are chosen to save to
+// like typed names, and don't need to exist.
+func (m *Model) acceptPath() tea.Cmd {
+	value := m.PathInput.Value()
+	path := m.expandPath(value)
+	info, err := m.fileSystem().Stat(path)
+	if err != nil && (!m.SaveMode || !errors.Is(err, fs.ErrNotExist)) {
+		m.pathErr = err
+		return nil
+	}
+
+	isDir := err == nil && info.IsDir()
+	if isDir && (m.SaveMode || !m.DirAllowed || strings.HasSuffix(value, "/") || strings.HasSuffix(value, string(filepath.Separator))) {
+		m.stopPathInput()
+		m.CurrentDirectory = path
+		m.filter = ""
+		m.selectedStack, m.minStack, m.maxStack = newStack(), newStack(), newStack()
+		m.selected, m.min, m.max = 0, 0, m.Height-1
+		return m.readDir(m.CurrentDirectory, m.ShowHidden)
+	}
+	if m.SaveMode {
+		return m.acceptSavePath(path)
+	}
+	if !isDir && (!m.FileAllowed || !m.canSelect(path)) {
+		m.pathErr = fmt.Errorf("%s can't be selected", filepath.Base(path))
+		return nil
+	}
+
+	m.stopPathInput()
+	m.Path = path
+	m.pathEntered = true
+	return nil
+}
+
+// acceptSavePath chooses the typed path to save to in save mode, adding the
+// first of the AllowedTypes if it has none of them. Its directory needs to
+// exist.
+func (m *Model) acceptSavePath(path string) tea.Cmd {
+	dir := filepath.Dir(path)
+	info, err := m.fileSystem().Stat(dir)
+	if err == nil && !info.IsDir() {
+		err = fmt.Errorf("%s isn't a folder", filepath.Base(dir))
+	}
+	if err != nil {
+		m.pathErr = err
+		return nil
+	}
+
+	path = m.withAllowedType(path)
+	if err := m.choosePath(path); err != nil {
+		m.pathErr = err
+		return nil
+	}
+	m.stopPathInput()
+	m.saveName = filepath.Base(path)
+	m.NameInput.SetValue(m.saveName)
+	return nil
+}
+
+// completePath loads the files of the directory being typed, unless they're
+// loaded already.
+func (m *Model) completePath() tea.Cmd {
+	value := m.PathInput.Value()
+	dir := value[:strings.LastIndexAny(value, "/"+string(filepath.Separator))+1]
+	if dir == m.completionDir {
+		return nil
+	}
+	m.completionDir = dir
+	m.PathInput.SetSuggestions(nil)
+
+	var (
+		id         = m.id
+		fsys       = m.fileSystem()
+		path       = m.expandPath(dir)
+		showHidden = m.ShowHidden
+	)
+	return func() tea.Msg {
+		entries, err := fsys.ReadDir(path)
+		if err != nil {
+			return nil
+		}
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			if hidden, _ := fsys.IsHidden(filepath.Join(path, e.Name())); hidden && !showHidden {
+				continue
+			}
+			name := dir + e.Name()
+			if e.IsDir() {
+				name += string(filepath.Separator)
+			}
+			names = append(names, name)
+		}
+		return completionMsg{id: id, dir: dir, names: names}
+	}
+}
+
+// expandPath expands a leading "~" to the home directory and makes relative
+// paths relative to the current directory.
+func (m Model) expandPath(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+	if path == "" {
+		return m.CurrentDirectory
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.CurrentDirectory, path)
+	}
+	return filepath.Clean(path)
+}
+
+// pathInputView renders the path input and the error of the last path
+// entered, if any.
+func (m Model) pathInputView() string {
+	if !m.enteringPath {
+		return ""
+	}
+	s := strings.Repeat(" ", paddingLeft) + m.PathInput.View()
+	if m.pathErr != nil {
+		s += " " + m.Styles.PathError.Render(m.pathErr.Error())
+	}
+	return s
+}
@@ -0,0 +1,224 @@
+package filepicker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/dustin/go-humanize"
+)
+
+const (
+	defaultPreviewLines    = 20
+	defaultPreviewMaxBytes = 64 * 1024
+	defaultPreviewWidth    = 40
+	previewTabWidth        = 4
+)
+
+// previewMsg carries a preview loaded in the background.
+type previewMsg struct {
+	id      int
+	path    string
+	info    fs.FileInfo
+	content string
+	err     error
+}
+
+// preview is the preview of a file.
+type preview struct {
+	path    string
+	loading bool
+	info    fs.FileInfo
+	content string
+	err     error
+
+	// entryInfo is the info of the file's directory entry when the preview
+	// was loaded, to tell whether the file changed since.
+	entryInfo fs.FileInfo
+}
+
+// selectedPath returns the path of the file under the cursor, if any.
+func (m Model) selectedPath() string {
+	if m.selected < 0 || m.selected >= len(m.files) {
+		return ""
+	}
+	return m.pathOf(m.files[m.selected])
+}
+
+// updatePreview starts loading the preview of the file under the cursor, if
+// it's not loaded yet or the file changed since it was loaded.
+func (m *Model) updatePreview() tea.Cmd {
+	if !m.ShowPreview {
+		return nil
+	}
+	path := m.selectedPath()
+	var info fs.FileInfo
+	if path != "" {
+		info, _ = m.files[m.selected].Info()
+	}
+	if path == m.preview.path && sameInfo(info, m.preview.entryInfo) {
+		return nil
+	}
+	m.preview = preview{path: path, loading: path != "", entryInfo: info}
+	if path == "" {
+		return nil
+	}
+	return m.loadPreview(path)
+}
+
+// sameInfo returns whether two infos of a file have the same size and
+// modification time, as far as they're known.
+func sameInfo(a, b fs.FileInfo) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
+
+// loadPreview reads the preview of the file at the given path: metadata, and
+// the first lines of text files or the files in directories.
+func (m Model) loadPreview(path string) tea.Cmd {
+	var (
+		id       = m.id
+		fsys     = m.fileSystem()
+		lines    = m.PreviewLines
+		maxBytes = m.PreviewMaxBytes
+	)
+	if lines <= 0 {
+		lines = defaultPreviewLines
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultPreviewMaxBytes
+	}
+
+	return func() tea.Msg {
+		msg := previewMsg{id: id, path: path}
+		msg.info, msg.err = fsys.Stat(path)
+		if msg.err != nil {
+			return msg
+		}
+		if msg.info.IsDir() {
+			msg.content, msg.err = previewDir(fsys, path, lines)
+		} else {
+			msg.content, msg.err = previewFile(fsys, path, lines, maxBytes)
+		}
+		return msg
+	}
+}
+
+// previewDir lists the first files in a directory.
+func previewDir(fsys FS, path string, lines int) (string, error) {
+	entries, err := fsys.ReadDir(path)
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+	if len(entries) == 0 {
+		return "(empty directory)", nil
+	}
+
+	var s strings.Builder
+	for i, e := range entries {
+		if i == lines {
+			fmt.Fprintf(&s, "… %d more", len(entries)-lines)
+			break
+		}
+		s.WriteString(e.Name())
+		if e.IsDir() {
+			s.WriteRune('/')
+		}
+		s.WriteRune('\n')
+	}
+	return strings.TrimSuffix(s.String(), "\n"), nil
+}
+
+// previewFile reads the first lines of a text file, reading at most maxBytes.
+func previewFile(fsys FS, path string, lines int, maxBytes int64) (string, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+	defer f.Close() //nolint:errcheck
+
+	b, err := io.ReadAll(io.LimitReader(f, maxBytes))
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+	if isBinary(b) {
+		return "(binary file)", nil
+	}
+
+	text := strings.ReplaceAll(string(b), "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\t", strings.Repeat(" ", previewTabWidth))
+	parts := strings.SplitN(text, "\n", lines+1)
+	return strings.Join(parts[:min(len(parts), lines)], "\n"), nil
+}
+
+// isBinary guesses whether the start of a file belongs to a binary file: it
+// contains NUL bytes or isn't valid UTF-8, ignoring a rune cut off at the
+// end.
+func isBinary(b []byte) bool {
+	if bytes.IndexByte(b, 0) >= 0 {
+		return true
+	}
+	for range utf8.UTFMax - 1 {
+		if len(b) == 0 || utf8.Valid(b) {
+			return false
+		}
+		b = b[:len(b)-1]
+	}
+	return !utf8.Valid(b)
+}
+
+// previewView renders the preview pane with the given height.
+func (m Model) previewView(height int) string {
+	width := m.PreviewWidth
+	if width <= 0 {
+		width = defaultPreviewWidth
+	}
+	style := m.Styles.Preview
+	width -= style.GetHorizontalBorderSize() + style.GetHorizontalMargins()
+	height -= style.GetVerticalBorderSize() + style.GetVerticalMargins()
+	style = style.Width(width).Height(height).MaxHeight(height)
+	inner := width - style.GetHorizontalPadding()
+
+	var s strings.Builder
+	switch p := m.preview; {
+	case p.path == "":
+	case p.loading:
+		s.WriteString(m.Styles.PreviewMeta.Render("Loading…"))
+	case p.err != nil:
+		s.WriteString(m.Styles.PreviewMeta.Render(p.err.Error()))
+	default:
+		meta := p.info.Mode().String()
+		if !p.info.IsDir() {
+			meta += " " + strings.Replace(humanize.Bytes(uint64(p.info.Size())), " ", "", 1) //nolint:gosec
+		}
+		if !p.info.ModTime().IsZero() {
+			meta += " " + p.info.ModTime().Format("2006-01-02 15:04")
+		}
+		s.WriteString(m.Styles.PreviewMeta.Render(ansi.Truncate(meta, inner, "…")))
+		s.WriteString("\n\n")
+		for i, line := range strings.Split(p.content, "\n") {
+			if i > 0 {
+				s.WriteRune('\n')
+			}
+			s.WriteString(ansi.Truncate(ansi.Strip(line), inner, "…"))
+		}
+	}
+	return style.Render(s.String())
+}
+
+// withPreview renders the preview pane next to the files, if enabled, as high
+// as the given number of rows of files.
+func (m Model) withPreview(files string, height int) string {
+	if !m.ShowPreview {
+		return files
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, files, m.previewView(height))
+}
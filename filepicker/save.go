@@ -0,0 +1,207 @@
+package filepicker
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// MkdirFS is an FS in which directories can be created, which is needed for
+// the NewFolder action in save mode.
+type MkdirFS interface {
+	FS
+
+	// Mkdir creates a directory with the given permissions.
+	Mkdir(path string, perm fs.FileMode) error
+}
+
+const newFolderPerm = 0o755
+
+// errNoMkdir is reported when creating a folder in a read-only filesystem.
+var errNoMkdir = errors.New("can't create folders here")
+
+// DidChooseSavePath returns whether the user chose where to save a file (on
+// this msg) in save mode, and the chosen path. If the file exists, the user
+// confirmed overwriting it.
+func (m Model) DidChooseSavePath(msg tea.Msg) (bool, string) {
+	if _, ok := msg.(tea.KeyMsg); !ok || !m.saveChosen {
+		return false, ""
+	}
+	return true, m.Path
+}
+
+// EditingName returns whether the user is typing the name of a file to save
+// or of a folder to create.
+func (m Model) EditingName() bool {
+	return m.editingName
+}
+
+// startNameInput focuses the name input. When creating a folder it starts out
+// empty, while the name of the file to save is kept between edits.
+func (m *Model) startNameInput(folder bool) tea.Cmd {
+	m.editingName = true
+	m.creatingFolder = folder
+	m.saveErr = nil
+	if folder {
+		m.NameInput.SetValue("")
+	}
+	m.NameInput.CursorEnd()
+	return m.NameInput.Focus()
+}
+
+// stopNameInput blurs the name input. When creating a folder, the name of the
+// file to save is restored.
+func (m *Model) stopNameInput() {
+	m.editingName = false
+	m.NameInput.Blur()
+	if m.creatingFolder {
+		m.creatingFolder = false
+		m.NameInput.SetValue(m.saveName)
+	}
+}
+
+// updateSave handles key presses while the user is typing a name or
+// confirming to overwrite a file.
+func (m *Model) updateSave(msg tea.KeyMsg) tea.Cmd {
+	if m.overwritePath != "" {
+		switch {
+		case key.Matches(msg, m.KeyMap.ConfirmOverwrite):
+			m.Path, m.saveChosen = m.overwritePath, true
+			m.overwritePath = ""
+		case key.Matches(msg, m.KeyMap.CancelOverwrite):
+			m.overwritePath = ""
+		}
+		return nil
+	}
+
+	switch {
+	case key.Matches(msg, m.KeyMap.CancelName):
+		m.stopNameInput()
+		return nil
+	case key.Matches(msg, m.KeyMap.AcceptName):
+		if m.creatingFolder {
+			return m.createFolder()
+		}
+		return m.chooseSavePath()
+	}
+
+	m.saveErr = nil
+	var cmd tea.Cmd
+	m.NameInput, cmd = m.NameInput.Update(msg)
+	if !m.creatingFolder {
+		m.saveName = m.NameInput.Value()
+	}
+	return cmd
+}
+
+// chooseSavePath chooses the typed name in the current directory, adding the
+// first of the AllowedTypes if the name has none of them. Existing files need
+// to be confirmed, while folders and names of other directories are refused.
+func (m *Model) chooseSavePath() tea.Cmd {
+	name := strings.TrimSpace(m.NameInput.Value())
+	if name == "" {
+		return nil
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, "/"+string(filepath.Separator)) {
+		m.saveErr = fmt.Errorf("%s isn't a file name", name)
+		return nil
+	}
+	name = m.withAllowedType(name)
+	m.NameInput.SetValue(name)
+	m.saveName = name
+
+	if err := m.choosePath(filepath.Join(m.CurrentDirectory, name)); err != nil {
+		m.saveErr = err
+		return nil
+	}
+	m.stopNameInput()
+	return nil
+}
+
+// choosePath chooses to save at the given path, which needs to be confirmed
+// if the file exists. An error is returned if the path is a folder or can't
+// be checked.
+func (m *Model) choosePath(path string) error {
+	info, err := m.fileSystem().Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		return fmt.Errorf("%s is a folder", filepath.Base(path))
+	case err == nil:
+		m.overwritePath = path
+	case errors.Is(err, fs.ErrNotExist):
+		m.Path, m.saveChosen = path, true
+	default:
+		return err
+	}
+	return nil
+}
+
+// withAllowedType adds the first of the AllowedTypes to a name with none of
+// them.
+func (m Model) withAllowedType(name string) string {
+	if m.canSelect(name) {
+		return name
+	}
+	return name + m.AllowedTypes[0]
+}
+
+// createFolder creates a folder with the typed name in the current directory
+// and reloads it.
+func (m *Model) createFolder() tea.Cmd {
+	name := strings.TrimSpace(m.NameInput.Value())
+	if name == "" {
+		return nil
+	}
+	fsys, ok := m.fileSystem().(MkdirFS)
+	if !ok {
+		m.saveErr = errNoMkdir
+		return nil
+	}
+	if err := fsys.Mkdir(filepath.Join(m.CurrentDirectory, name), newFolderPerm); err != nil {
+		m.saveErr = err
+		return nil
+	}
+	m.stopNameInput()
+	return m.readDir(m.CurrentDirectory, m.ShowHidden)
+}
+
+// useSelectedName fills the name input with the name of the file under the
+// cursor, to save over it.
+func (m *Model) useSelectedName() tea.Cmd {
+	m.saveName = m.files[m.selected].Name()
+	m.NameInput.SetValue(m.saveName)
+	return m.startNameInput(false)
+}
+
+// saveView renders the name input, or the overwrite confirmation, in save
+// mode.
+func (m Model) saveView() string {
+	if !m.SaveMode {
+		return ""
+	}
+	pad := strings.Repeat(" ", paddingLeft)
+	if m.overwritePath != "" {
+		return pad + m.Styles.PathError.Render(fmt.Sprintf(
+			"%s already exists. Overwrite? (%s/%s)",
+			filepath.Base(m.overwritePath),
+			m.KeyMap.ConfirmOverwrite.Help().Key,
+			m.KeyMap.CancelOverwrite.Help().Key,
+		))
+	}
+
+	prompt := "Save as: "
+	if m.creatingFolder {
+		prompt = "New folder: "
+	}
+	m.NameInput.Prompt = prompt
+	s := pad + m.NameInput.View()
+	if m.saveErr != nil {
+		s += " " + m.Styles.PathError.Render(m.saveErr.Error())
+	}
+	return s
+}
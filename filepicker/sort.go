@@ -0,0 +1,202 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// SortOrder is the order in which files are listed. Directories are always
+// listed before files.
+type SortOrder int
+
+// Sort orders.
+const (
+	// SortByName sorts files by name.
+	SortByName SortOrder = iota
+
+	// SortByNatural sorts files by name, comparing runs of digits by their
+	// numeric value, so "file2" comes before "file10".
+	SortByNatural
+
+	// SortBySize sorts files by size, smallest first.
+	SortBySize
+
+	// SortByModTime sorts files by modification time, oldest first.
+	SortByModTime
+
+	// SortByExtension sorts files by extension, then by name.
+	SortByExtension
+)
+
+// String returns a short name for the sort order.
+func (o SortOrder) String() string {
+	if o < SortByName || o > SortByExtension {
+		return "unknown"
+	}
+	return [...]string{
+		"name",
+		"natural",
+		"size",
+		"modified",
+		"extension",
+	}[o]
+}
+
+// Next returns the sort order following this one, wrapping around.
+func (o SortOrder) Next() SortOrder {
+	return (o + 1) % (SortByExtension + 1)
+}
+
+// sortFiles sorts files in place, directories first.
+func sortFiles(files []os.DirEntry, order SortOrder, descending bool) {
+	// Look up the size or modification time of each file once, rather than
+	// in every comparison, and sort the indices of the files.
+	var keys []int64
+	switch order {
+	case SortBySize:
+		keys = make([]int64, len(files))
+		for i, f := range files {
+			keys[i] = infoSize(f)
+		}
+	case SortByModTime:
+		keys = make([]int64, len(files))
+		for i, f := range files {
+			keys[i] = infoModTime(f)
+		}
+	}
+
+	less := func(a, b int) bool {
+		return files[a].Name() < files[b].Name()
+	}
+	switch order {
+	case SortByName:
+	case SortByNatural:
+		less = func(a, b int) bool {
+			return naturalLess(files[a].Name(), files[b].Name())
+		}
+	case SortBySize, SortByModTime:
+		less = func(a, b int) bool {
+			if keys[a] == keys[b] {
+				return files[a].Name() < files[b].Name()
+			}
+			return keys[a] < keys[b]
+		}
+	case SortByExtension:
+		less = func(a, b int) bool {
+			ea, eb := filepath.Ext(files[a].Name()), filepath.Ext(files[b].Name())
+			if ea == eb {
+				return files[a].Name() < files[b].Name()
+			}
+			return ea < eb
+		}
+	}
+
+	indices := make([]int, len(files))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		a, b := indices[i], indices[j]
+		if files[a].IsDir() != files[b].IsDir() {
+			return files[a].IsDir()
+		}
+		if descending {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+
+	sorted := make([]os.DirEntry, len(files))
+	for i, k := range indices {
+		sorted[i] = files[k]
+	}
+	copy(files, sorted)
+}
+
+func infoSize(f os.DirEntry) int64 {
+	info, err := f.Info()
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+func infoModTime(f os.DirEntry) int64 {
+	info, err := f.Info()
+	if err != nil {
+		return 0
+	}
+	return info.ModTime().UnixNano()
+}
+
+// naturalLess compares strings, treating runs of digits as numbers.
+func naturalLess(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	i, j := 0, 0
+	for i < len(ra) && j < len(rb) {
+		if !unicode.IsDigit(ra[i]) || !unicode.IsDigit(rb[j]) {
+			if ra[i] != rb[j] {
+				return ra[i] < rb[j]
+			}
+			i++
+			j++
+			continue
+		}
+
+		// Compare the runs of digits by value: ignoring leading zeros, the
+		// longer run is larger, and runs of the same length compare
+		// lexically.
+		si, sj := i, j
+		for i < len(ra) && unicode.IsDigit(ra[i]) {
+			i++
+		}
+		for j < len(rb) && unicode.IsDigit(rb[j]) {
+			j++
+		}
+		na := strings.TrimLeft(string(ra[si:i]), "0")
+		nb := strings.TrimLeft(string(rb[sj:j]), "0")
+		if len(na) != len(nb) {
+			return len(na) < len(nb)
+		}
+		if na != nb {
+			return na < nb
+		}
+	}
+	if len(ra)-i != len(rb)-j {
+		return len(ra)-i < len(rb)-j
+	}
+	return a < b
+}
+
+// SetSortOrder changes the order in which files are listed.
+func (m *Model) SetSortOrder(order SortOrder, descending bool) {
+	m.SortOrder, m.SortDescending = order, descending
+	m.resort()
+}
+
+// resort sorts the loaded files again, keeping the selected file selected.
+func (m *Model) resort() {
+	if m.TreeView {
+		for dir, files := range m.children {
+			files = slices.Clone(files)
+			sortFiles(files, m.SortOrder, m.SortDescending)
+			m.children[dir] = files
+		}
+		m.rebuildTree()
+		return
+	}
+
+	// Sort a copy, since the files may be shared with the current view.
+	files := slices.Clone(m.allFiles)
+	sortFiles(files, m.SortOrder, m.SortDescending)
+	m.allFiles = files
+
+	top, bottom := m.min, m.max
+	m.applyFilter()
+	m.min, m.max = top, bottom
+	m.keepSelectedVisible()
+}
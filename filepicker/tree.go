@@ -0,0 +1,188 @@
+package filepicker
+
+import (
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// treeEntry is a file shown in tree view, which may live in a subdirectory
+// of the current directory.
+type treeEntry struct {
+	os.DirEntry
+
+	// dir is the directory containing the file.
+	dir string
+
+	// guide holds the indentation guides drawn before the file name.
+	guide string
+}
+
+// dirOf returns the directory containing the given file.
+func (m Model) dirOf(f os.DirEntry) string {
+	if e, ok := f.(treeEntry); ok {
+		return e.dir
+	}
+	return m.CurrentDirectory
+}
+
+// pathOf returns the path of the given file.
+func (m Model) pathOf(f os.DirEntry) string {
+	return filepath.Join(m.dirOf(f), f.Name())
+}
+
+// guideOf returns the indentation guides of the given file in tree view.
+func guideOf(f os.DirEntry) string {
+	if e, ok := f.(treeEntry); ok {
+		return e.guide
+	}
+	return ""
+}
+
+// IsExpanded returns whether the directory at the given path is expanded in
+// tree view.
+func (m Model) IsExpanded(path string) bool {
+	return m.expanded[path]
+}
+
+// expand expands the directory under the cursor, loading its files.
+func (m *Model) expand() tea.Cmd {
+	if m.selected < 0 || m.selected >= len(m.files) {
+		return nil
+	}
+	f := m.files[m.selected]
+	if !m.resolvesToDir(f) {
+		return nil
+	}
+	path := m.pathOf(f)
+	if m.expanded == nil {
+		m.expanded = make(map[string]bool)
+	}
+	m.expanded[path] = true
+	m.rebuildTree()
+
+	// Show the files loaded last time right away, and refresh them.
+	return m.readDir(path, m.ShowHidden)
+}
+
+// canCollapse returns whether the Collapse key applies to the file under the
+// cursor, which is an expanded directory or lives in one. Otherwise the key
+// goes back to the parent directory like Back.
+func (m Model) canCollapse() bool {
+	if m.selected < 0 || m.selected >= len(m.files) {
+		return false
+	}
+	f := m.files[m.selected]
+	return m.expanded[m.pathOf(f)] || m.dirOf(f) != m.CurrentDirectory
+}
+
+// collapse collapses the directory under the cursor, or else moves the
+// cursor to the directory containing the file under the cursor.
+func (m *Model) collapse() {
+	if m.selected < 0 || m.selected >= len(m.files) {
+		return
+	}
+	f := m.files[m.selected]
+	if path := m.pathOf(f); m.expanded[path] {
+		delete(m.expanded, path)
+		m.rebuildTree()
+		return
+	}
+	dir := m.dirOf(f)
+	for i, parent := range m.files {
+		if m.pathOf(parent) == dir {
+			m.selected = i
+			m.keepSelectedVisible()
+			return
+		}
+	}
+}
+
+// rebuildTree lays out the loaded files of the current directory and its
+// expanded subdirectories, keeping the selected file selected.
+func (m *Model) rebuildTree() {
+	var current string
+	if m.selected >= 0 && m.selected < len(m.files) {
+		current = m.pathOf(m.files[m.selected])
+	}
+
+	var files []os.DirEntry
+	m.layoutTree(m.CurrentDirectory, "", &files)
+	m.allFiles = files
+	if m.filter != "" {
+		m.applyFilter()
+		return
+	}
+
+	m.files, m.matches = files, nil
+	for i, f := range m.files {
+		if m.pathOf(f) == current {
+			m.selected = i
+			break
+		}
+	}
+	m.selected = min(m.selected, max(0, len(m.files)-1))
+	m.keepSelectedVisible()
+}
+
+// layoutTree appends the files of the given directory, followed by the files
+// of each of its expanded subdirectories, to files.
+func (m Model) layoutTree(dir, indent string, files *[]os.DirEntry) {
+	entries := m.children[dir]
+	for i, f := range entries {
+		connector, next := "├─ ", "│  "
+		if i == len(entries)-1 {
+			connector, next = "└─ ", "   "
+		}
+		*files = append(*files, treeEntry{DirEntry: f, dir: dir, guide: indent + connector})
+
+		if path := filepath.Join(dir, f.Name()); m.expanded[path] {
+			m.layoutTree(path, indent+next, files)
+		}
+	}
+}
+
+// updateTree stores files read in tree view. Reading a new current directory
+// starts a new tree, while reloading it keeps its expanded subdirectories, and
+// the files of these are laid out below them.
+func (m *Model) updateTree(msg readDirMsg) {
+	switch {
+	case msg.path == m.CurrentDirectory && msg.path != m.treeRoot:
+		m.treeRoot = msg.path
+		m.children, m.expanded = nil, nil
+		m.max = max(m.max, m.Height-1)
+	case msg.path != m.CurrentDirectory && !m.expanded[msg.path]:
+		return
+	}
+	if m.children == nil {
+		m.children = make(map[string][]os.DirEntry)
+	}
+	m.children[msg.path] = msg.entries
+	m.rebuildTree()
+}
+
+// syncTreeView lays out the loaded files again when TreeView was turned on or
+// off since they were loaded, and reloads the current directory.
+func (m *Model) syncTreeView() tea.Cmd {
+	if m.allFiles == nil || m.TreeView == (m.children != nil) {
+		return nil
+	}
+	if m.TreeView {
+		m.treeRoot = m.CurrentDirectory
+		m.children = map[string][]os.DirEntry{m.CurrentDirectory: m.allFiles}
+		m.expanded = nil
+		m.rebuildTree()
+	} else {
+		m.allFiles = m.children[m.CurrentDirectory]
+		m.children, m.expanded = nil, nil
+		if m.filter != "" {
+			m.applyFilter()
+		} else {
+			m.files, m.matches = m.allFiles, nil
+		}
+		m.selected = min(m.selected, max(0, len(m.files)-1))
+		m.keepSelectedVisible()
+	}
+	return m.readDir(m.CurrentDirectory, m.ShowHidden)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
@@ -22,12 +23,20 @@ type DefaultItemStyles struct {
 	SelectedTitle lipgloss.Style
 	SelectedDesc  lipgloss.Style
 
+	// The marked state, for items marked when multi-select is enabled. The
+	// border of MarkedTitle is also used for marked items under the cursor.
+	MarkedTitle lipgloss.Style
+	MarkedDesc  lipgloss.Style
+
 	// The dimmed state, for when the filter input is initially activated.
 	DimmedTitle lipgloss.Style
 	DimmedDesc  lipgloss.Style
 
 	// Characters matching the current filter, if any.
 	FilterMatch lipgloss.Style
+
+	// Badges set with Model.SetItemBadge, rendered after the title.
+	Badge lipgloss.Style
 }
 
 // NewDefaultItemStyles returns style definitions for a default item. See
@@ -49,6 +58,15 @@ func NewDefaultItemStyles() (s DefaultItemStyles) {
 	s.SelectedDesc = s.SelectedTitle.
 		Foreground(lipgloss.AdaptiveColor{Light: "#F793FF", Dark: "#AD58B4"})
 
+	s.MarkedTitle = lipgloss.NewStyle().
+		Border(lipgloss.ThickBorder(), false, false, false, true).
+		BorderForeground(lipgloss.AdaptiveColor{Light: "#04B575", Dark: "#04B575"}).
+		Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#dddddd"}).
+		Padding(0, 0, 0, 1)
+
+	s.MarkedDesc = s.MarkedTitle.
+		Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"})
+
 	s.DimmedTitle = lipgloss.NewStyle().
 		Foreground(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"}).
 		Padding(0, 0, 0, 2) //nolint:mnd
@@ -58,6 +76,11 @@ func NewDefaultItemStyles() (s DefaultItemStyles) {
 
 	s.FilterMatch = lipgloss.NewStyle().Underline(true)
 
+	s.Badge = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#FFFDF5")).
+		Background(lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#5C5C5C"}).
+		Padding(0, 1)
+
 	return s
 }
 
@@ -156,9 +179,21 @@ func (d DefaultDelegate) Render(w io.Writer, m Model, index int, item Item) {
 		return
 	}
 
+	// Item spinners and badges
+	var prefix, badge string
+	if i, ok := m.globalIndexOf(index); ok {
+		if m.ItemBusy(i) {
+			prefix = ansi.Strip(m.ItemSpinnerView()) + " "
+		}
+		if b := m.ItemBadge(i); b != "" {
+			badge = " " + s.Badge.Render(b)
+		}
+	}
+
 	// Prevent text from exceeding list width
 	textwidth := m.width - s.NormalTitle.GetPaddingLeft() - s.NormalTitle.GetPaddingRight()
-	title = ansi.Truncate(title, textwidth, ellipsis)
+	title = ansi.Truncate(title, textwidth-ansi.StringWidth(prefix+badge), ellipsis)
+	title = prefix + title
 	if d.ShowDescription {
 		var lines []string
 		for i, line := range strings.Split(desc, "\n") {
@@ -173,13 +208,17 @@ func (d DefaultDelegate) Render(w io.Writer, m Model, index int, item Item) {
 	// Conditions
 	var (
 		isSelected  = index == m.Index()
+		isMarked    = m.IsMarked(index)
 		emptyFilter = m.FilterState() == Filtering && m.FilterValue() == ""
 		isFiltered  = m.FilterState() == Filtering || m.FilterState() == FilterApplied
 	)
 
 	if isFiltered && index < len(m.filteredItems) {
-		// Get indices of matched characters
-		matchedRunes = m.MatchesForItem(index)
+		// Get indices of matched characters, past the spinner
+		offset := utf8.RuneCountInString(prefix)
+		for _, r := range m.MatchesForItem(index) {
+			matchedRunes = append(matchedRunes, r+offset)
+		}
 	}
 
 	if emptyFilter {
@@ -192,8 +231,22 @@ func (d DefaultDelegate) Render(w io.Writer, m Model, index int, item Item) {
 			matched := unmatched.Inherit(s.FilterMatch)
 			title = lipgloss.StyleRunes(title, matchedRunes, matched, unmatched)
 		}
-		title = s.SelectedTitle.Render(title)
-		desc = s.SelectedDesc.Render(desc)
+		selectedTitle, selectedDesc := s.SelectedTitle, s.SelectedDesc
+		if isMarked {
+			selectedTitle = selectedTitle.BorderStyle(s.MarkedTitle.GetBorderStyle())
+			selectedDesc = selectedDesc.BorderStyle(s.MarkedDesc.GetBorderStyle())
+		}
+		title = selectedTitle.Render(title)
+		desc = selectedDesc.Render(desc)
+	} else if isMarked {
+		if isFiltered {
+			// Highlight matches
+			unmatched := s.MarkedTitle.Inline(true)
+			matched := unmatched.Inherit(s.FilterMatch)
+			title = lipgloss.StyleRunes(title, matchedRunes, matched, unmatched)
+		}
+		title = s.MarkedTitle.Render(title)
+		desc = s.MarkedDesc.Render(desc)
 	} else {
 		if isFiltered {
 			// Highlight matches
@@ -205,6 +258,8 @@ func (d DefaultDelegate) Render(w io.Writer, m Model, index int, item Item) {
 		desc = s.NormalDesc.Render(desc)
 	}
 
+	title += badge
+
 	if d.ShowDescription {
 		fmt.Fprintf(w, "%s\n%s", title, desc) //nolint: errcheck
 		return
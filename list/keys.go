@@ -15,6 +15,18 @@ type KeyMap struct {
 	Filter      key.Binding
 	ClearFilter key.Binding
 
+	// Keybindings used for marking items when multi-select is enabled.
+	ToggleMark  key.Binding
+	MarkUp      key.Binding
+	MarkDown    key.Binding
+	MarkAll     key.Binding
+	UnmarkAll   key.Binding
+	InvertMarks key.Binding
+
+	// Keybindings used for moving items when the list is reorderable.
+	MoveUp   key.Binding
+	MoveDown key.Binding
+
 	// Keybindings used when setting a filter.
 	CancelWhileFiltering key.Binding
 	AcceptWhileFiltering key.Binding
@@ -67,6 +79,42 @@ func DefaultKeyMap() KeyMap {
 			key.WithHelp("esc", "clear filter"),
 		),
 
+		// Marking.
+		ToggleMark: key.NewBinding(
+			key.WithKeys(" ", "x"),
+			key.WithHelp("space/x", "mark"),
+		),
+		MarkUp: key.NewBinding(
+			key.WithKeys("shift+up", "K"),
+			key.WithHelp("shift+↑/K", "mark up"),
+		),
+		MarkDown: key.NewBinding(
+			key.WithKeys("shift+down", "J"),
+			key.WithHelp("shift+↓/J", "mark down"),
+		),
+		MarkAll: key.NewBinding(
+			key.WithKeys("a", "ctrl+a"),
+			key.WithHelp("a", "mark all"),
+		),
+		UnmarkAll: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "unmark all"),
+		),
+		InvertMarks: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "invert marks"),
+		),
+
+		// Reordering.
+		MoveUp: key.NewBinding(
+			key.WithKeys("alt+up", "alt+k"),
+			key.WithHelp("alt+↑/k", "move up"),
+		),
+		MoveDown: key.NewBinding(
+			key.WithKeys("alt+down", "alt+j"),
+			key.WithHelp("alt+↓/j", "move down"),
+		),
+
 		// Filtering.
 		CancelWhileFiltering: key.NewBinding(
 			key.WithKeys("esc"),
@@ -5,6 +5,7 @@ package list
 
 import (
 	"cmp"
+	"context"
 	"fmt"
 	"io"
 	"sort"
@@ -60,6 +61,17 @@ type ItemDelegate interface {
 	Update(msg tea.Msg, m *Model) tea.Cmd
 }
 
+// VariableHeightDelegate is an optional interface for delegates rendering
+// items of varying height, e.g. items with optional descriptions or wrapped
+// text. Pages then hold as many items as fit, and Height is only used to
+// estimate how many items make up a page.
+type VariableHeightDelegate interface {
+	ItemDelegate
+
+	// HeightOf is the height of the given item.
+	HeightOf(item Item) int
+}
+
 type filteredItem struct {
 	index   int   // index in the unfiltered list
 	item    Item  // item matched
@@ -124,6 +136,19 @@ func UnsortedFilter(term string, targets []string) []Rank {
 
 type statusMessageTimeoutMsg struct{}
 
+// filterDebounceMsg is sent once the filter input has been idle for the
+// debounce duration.
+type filterDebounceMsg struct {
+	id int
+}
+
+// asyncFilterMatchesMsg carries the result of an asynchronous filter query.
+type asyncFilterMatchesMsg struct {
+	id      int
+	query   string
+	matches filteredItems
+}
+
 // FilterState describes the current filtering state on the model.
 type FilterState int
 
@@ -151,6 +176,9 @@ type Model struct {
 	showPagination   bool
 	showHelp         bool
 	filteringEnabled bool
+	multiSelect      bool
+	reorderable      bool
+	dragAndDrop      bool
 
 	itemNameSingular string
 	itemNamePlural   string
@@ -159,12 +187,42 @@ type Model struct {
 	Styles            Styles
 	InfiniteScrolling bool
 
+	// ScrollMargin is the number of items kept in view above and below the
+	// cursor in scroll mode, like Vim's scrolloff.
+	ScrollMargin int
+
+	// MouseWheelDelta is the number of lines the mouse wheel scrolls in
+	// scroll mode. By default this is 3.
+	MouseWheelDelta int
+
 	// Key mappings for navigating the list.
 	KeyMap KeyMap
 
 	// Filter is used to filter the list.
 	Filter FilterFunc
 
+	// ItemFilter, if set, is used to filter the list instead of Filter. Set
+	// it to QueryFilter for a query language supporting phrases, negation,
+	// field qualifiers and alternatives.
+	ItemFilter ItemFilterFunc
+
+	// AsyncFilter runs filter queries in the background, discarding the
+	// results of queries superseded by newer input. By default filtering
+	// runs on every keystroke and results are applied in the order they
+	// arrive.
+	AsyncFilter bool
+
+	// FilterDebounce delays asynchronous filtering until the filter input
+	// has been idle for the given duration.
+	FilterDebounce time.Duration
+
+	// IncrementalFilter makes asynchronous filtering only search the
+	// matches of the previous query when the new query extends it. This
+	// assumes that an item matching a query also matches its prefixes,
+	// which holds for DefaultFilter and UnsortedFilter. It has no effect when
+	// ItemFilter is set.
+	IncrementalFilter bool
+
 	disableQuitKeybindings bool
 
 	// Additional key mappings for the short and full help views. This allows
@@ -200,6 +258,48 @@ type Model struct {
 	// this field should be considered ephemeral.
 	filteredItems filteredItems
 
+	// State of asynchronous filtering: the ID and context of the latest
+	// query, and the query and matches used as a base for narrowing.
+	filterID      int
+	filterCtx     context.Context //nolint:containedctx
+	filterCancel  context.CancelFunc
+	filterBase    string
+	filterBaseSet filteredItems
+
+	// filterTargets caches the filter values of the items between queries.
+	filterTargets []string
+
+	// Indices of marked items in the unfiltered list.
+	marked map[int]struct{}
+
+	// Transient per-item state, by index in the unfiltered list: badges, and
+	// items showing a spinner driven by itemSpinner.
+	badges      map[int]string
+	busy        map[int]struct{}
+	itemSpinner spinner.Model
+
+	// The number of skeleton items shown while there are no items.
+	placeholders int
+
+	// Index of the first visible item on each page, when pages hold a
+	// varying number of items, e.g. because of section headers.
+	pageStarts []int
+
+	// The number of lines available to items.
+	viewHeight int
+
+	// State of scroll mode: the lines scrolled past, and the first line of
+	// each visible item when items take a varying number of lines.
+	scrollMode   bool
+	scrollOffset int
+	lineTops     []int
+
+	// State of mouse dragging: where the list is rendered, and the index of
+	// the dragged item before the drag started.
+	posX, posY int
+	dragging   bool
+	dragFrom   int
+
 	delegate ItemDelegate
 }
 
@@ -218,6 +318,10 @@ func New(items []Item, delegate ItemDelegate, width, height int) Model {
 	filterInput.CharLimit = 64
 	filterInput.Focus()
 
+	itemSp := spinner.New()
+	itemSp.Spinner = spinner.MiniDot
+	itemSp.Style = styles.Spinner
+
 	p := paginator.New()
 	p.Type = paginator.Dots
 	p.ActiveDot = styles.ActivePaginationDot.String()
@@ -238,14 +342,16 @@ func New(items []Item, delegate ItemDelegate, width, height int) Model {
 		Title:                 "List",
 		FilterInput:           filterInput,
 		StatusMessageLifetime: time.Second,
+		MouseWheelDelta:       defaultMouseWheelDelta,
 
-		width:     width,
-		height:    height,
-		delegate:  delegate,
-		items:     items,
-		Paginator: p,
-		spinner:   sp,
-		Help:      help.New(),
+		width:       width,
+		height:      height,
+		delegate:    delegate,
+		items:       items,
+		Paginator:   p,
+		spinner:     sp,
+		itemSpinner: itemSp,
+		Help:        help.New(),
 	}
 
 	m.updatePagination()
@@ -285,6 +391,8 @@ func (m *Model) SetShowTitle(v bool) {
 func (m *Model) SetFilterText(filter string) {
 	m.filterState = Filtering
 	m.FilterInput.SetValue(filter)
+	m.cancelFilter()
+	m.updateFilterTargets()
 	cmd := filterItems(*m)
 	msg := cmd()
 	fmm, _ := msg.(FilterMatchesMsg)
@@ -385,10 +493,13 @@ func (m Model) Items() []Item {
 func (m *Model) SetItems(i []Item) tea.Cmd {
 	var cmd tea.Cmd
 	m.items = i
+	m.marked, m.badges, m.busy = nil, nil, nil
+	m.placeholders = 0
+	m.resetFilterBase()
 
 	if m.filterState != Unfiltered {
 		m.filteredItems = nil
-		cmd = filterItems(*m)
+		cmd = m.requestFilter()
 	}
 
 	m.updatePagination()
@@ -398,8 +509,10 @@ func (m *Model) SetItems(i []Item) tea.Cmd {
 
 // Select selects the given index of the list and goes to its respective page.
 func (m *Model) Select(index int) {
-	m.Paginator.Page = index / m.Paginator.PerPage
-	m.cursor = index % m.Paginator.PerPage
+	m.Paginator.Page = m.pageOf(index)
+	start, _ := m.pageBounds(m.Paginator.Page)
+	m.cursor = index - start
+	m.scrollToCursor()
 }
 
 // ResetSelected resets the selected item to the first item in the first page of the list.
@@ -416,9 +529,10 @@ func (m *Model) ResetFilter() {
 func (m *Model) SetItem(index int, item Item) tea.Cmd {
 	var cmd tea.Cmd
 	m.items[index] = item
+	m.resetFilterBase()
 
 	if m.filterState != Unfiltered {
-		cmd = filterItems(*m)
+		cmd = m.requestFilter()
 	}
 
 	m.updatePagination()
@@ -429,10 +543,14 @@ func (m *Model) SetItem(index int, item Item) tea.Cmd {
 // the item will be appended. This returns a command.
 func (m *Model) InsertItem(index int, item Item) tea.Cmd {
 	var cmd tea.Cmd
+	if index < len(m.items) {
+		m.shiftItemState(max(0, index), 1)
+	}
 	m.items = insertItemIntoSlice(m.items, item, index)
+	m.resetFilterBase()
 
 	if m.filterState != Unfiltered {
-		cmd = filterItems(*m)
+		cmd = m.requestFilter()
 	}
 
 	m.updatePagination()
@@ -444,7 +562,11 @@ func (m *Model) InsertItem(index int, item Item) tea.Cmd {
 // this will be a no-op. O(n) complexity, which probably won't matter in the
 // case of a TUI.
 func (m *Model) RemoveItem(index int) {
+	if index < len(m.items) {
+		m.shiftItemState(index, -1)
+	}
 	m.items = removeItemFromSlice(m.items, index)
+	m.resetFilterBase()
 	if m.filterState != Unfiltered {
 		m.filteredItems = removeFilterMatchFromSlice(m.filteredItems, index)
 		if len(m.filteredItems) == 0 {
@@ -496,7 +618,8 @@ func (m Model) MatchesForItem(index int) []int {
 // Using this value with SetItem() might be incorrect, consider using
 // GlobalIndex() instead.
 func (m Model) Index() int {
-	return m.Paginator.Page*m.Paginator.PerPage + m.cursor
+	start, _ := m.pageBounds(m.Paginator.Page)
+	return start + m.cursor
 }
 
 // GlobalIndex returns the index of the currently selected item as it is stored
@@ -519,6 +642,7 @@ func (m Model) Cursor() int {
 // CursorUp moves the cursor up. This can also move the state to the previous
 // page.
 func (m *Model) CursorUp() {
+	defer m.scrollToCursor()
 	m.cursor--
 
 	// If we're at the start, stop
@@ -545,6 +669,7 @@ func (m *Model) CursorUp() {
 // CursorDown moves the cursor down. This can also advance the state to the
 // next page.
 func (m *Model) CursorDown() {
+	defer m.scrollToCursor()
 	maxCursorIndex := m.maxCursorIndex()
 
 	m.cursor++
@@ -573,28 +698,41 @@ func (m *Model) CursorDown() {
 func (m *Model) GoToStart() {
 	m.Paginator.Page = 0
 	m.cursor = 0
+	m.scrollToCursor()
 }
 
 // GoToEnd moves to the last page, and last item on the last page.
 func (m *Model) GoToEnd() {
 	m.Paginator.Page = max(0, m.Paginator.TotalPages-1)
 	m.cursor = m.maxCursorIndex()
+	m.scrollToCursor()
 }
 
-// PrevPage moves to the previous page, if available.
+// PrevPage moves to the previous page, if available. In scroll mode this
+// moves the cursor up by a page worth of items.
 func (m *Model) PrevPage() {
+	if m.scrollMode {
+		m.Select(max(0, m.Index()-m.Paginator.PerPage))
+		return
+	}
 	m.Paginator.PrevPage()
 	m.cursor = clamp(m.cursor, 0, m.maxCursorIndex())
 }
 
-// NextPage moves to the next page, if available.
+// NextPage moves to the next page, if available. In scroll mode this moves
+// the cursor down by a page worth of items.
 func (m *Model) NextPage() {
+	if m.scrollMode {
+		m.Select(min(m.Index()+m.Paginator.PerPage, m.maxCursorIndex()))
+		return
+	}
 	m.Paginator.NextPage()
 	m.cursor = clamp(m.cursor, 0, m.maxCursorIndex())
 }
 
 func (m *Model) maxCursorIndex() int {
-	return max(0, m.Paginator.ItemsOnPage(len(m.VisibleItems()))-1)
+	start, end := m.pageBounds(m.Paginator.Page)
+	return max(0, end-start-1)
 }
 
 // FilterState returns the current filter state.
@@ -716,6 +854,8 @@ func (m *Model) resetFiltering() {
 	m.filterState = Unfiltered
 	m.FilterInput.Reset()
 	m.filteredItems = nil
+	m.cancelFilter()
+	m.resetFilterBase()
 	m.updatePagination()
 	m.updateKeybindings()
 }
@@ -724,7 +864,8 @@ func (m Model) itemsAsFilterItems() filteredItems {
 	fi := make([]filteredItem, len(m.items))
 	for i, item := range m.items {
 		fi[i] = filteredItem{
-			item: item,
+			index: i,
+			item:  item,
 		}
 	}
 	return fi
@@ -742,6 +883,14 @@ func (m *Model) updateKeybindings() {
 		m.KeyMap.GoToEnd.SetEnabled(false)
 		m.KeyMap.Filter.SetEnabled(false)
 		m.KeyMap.ClearFilter.SetEnabled(false)
+		m.KeyMap.ToggleMark.SetEnabled(false)
+		m.KeyMap.MarkUp.SetEnabled(false)
+		m.KeyMap.MarkDown.SetEnabled(false)
+		m.KeyMap.MarkAll.SetEnabled(false)
+		m.KeyMap.UnmarkAll.SetEnabled(false)
+		m.KeyMap.InvertMarks.SetEnabled(false)
+		m.KeyMap.MoveUp.SetEnabled(false)
+		m.KeyMap.MoveDown.SetEnabled(false)
 		m.KeyMap.CancelWhileFiltering.SetEnabled(true)
 		m.KeyMap.AcceptWhileFiltering.SetEnabled(m.FilterInput.Value() != "")
 		m.KeyMap.Quit.SetEnabled(false)
@@ -754,6 +903,9 @@ func (m *Model) updateKeybindings() {
 		m.KeyMap.CursorDown.SetEnabled(hasItems)
 
 		hasPages := m.Paginator.TotalPages > 1
+		if m.scrollMode {
+			hasPages = m.maxScrollOffset(m.VisibleItems()) > 0
+		}
 		m.KeyMap.NextPage.SetEnabled(hasPages)
 		m.KeyMap.PrevPage.SetEnabled(hasPages)
 
@@ -762,6 +914,18 @@ func (m *Model) updateKeybindings() {
 
 		m.KeyMap.Filter.SetEnabled(m.filteringEnabled && hasItems)
 		m.KeyMap.ClearFilter.SetEnabled(m.filterState == FilterApplied)
+
+		canMark := m.multiSelect && hasItems
+		m.KeyMap.ToggleMark.SetEnabled(canMark)
+		m.KeyMap.MarkUp.SetEnabled(canMark)
+		m.KeyMap.MarkDown.SetEnabled(canMark)
+		m.KeyMap.MarkAll.SetEnabled(canMark)
+		m.KeyMap.UnmarkAll.SetEnabled(canMark)
+		m.KeyMap.InvertMarks.SetEnabled(canMark)
+
+		m.KeyMap.MoveUp.SetEnabled(m.canMove())
+		m.KeyMap.MoveDown.SetEnabled(m.canMove())
+
 		m.KeyMap.CancelWhileFiltering.SetEnabled(false)
 		m.KeyMap.AcceptWhileFiltering.SetEnabled(false)
 		m.KeyMap.Quit.SetEnabled(!m.disableQuitKeybindings)
@@ -797,20 +961,31 @@ func (m *Model) updatePagination() {
 
 	m.Paginator.PerPage = max(1, availHeight/(m.delegate.Height()+m.delegate.Spacing()))
 
-	if pages := len(m.VisibleItems()); pages < 1 {
+	items := m.VisibleItems()
+	m.viewHeight = max(0, availHeight)
+	m.pageStarts, m.lineTops = m.layoutPages(items, availHeight), nil
+	if m.scrollMode {
+		// Scroll mode is a single page holding all items.
+		m.pageStarts, m.lineTops = []int{0}, m.layoutLines(items)
+	}
+	switch {
+	case m.pageStarts != nil:
+		m.Paginator.TotalPages = max(1, len(m.pageStarts))
+	case len(items) < 1:
 		m.Paginator.SetTotalPages(1)
-	} else {
-		m.Paginator.SetTotalPages(pages)
+	default:
+		m.Paginator.SetTotalPages(len(items))
 	}
 
 	// Restore index
-	m.Paginator.Page = index / m.Paginator.PerPage
-	m.cursor = index % m.Paginator.PerPage
+	m.Select(index)
 
 	// Make sure the page stays in bounds
 	if m.Paginator.Page >= m.Paginator.TotalPages-1 {
 		m.Paginator.Page = max(0, m.Paginator.TotalPages-1)
 	}
+
+	m.scrollToCursor()
 }
 
 func (m *Model) hideStatusMessage() {
@@ -832,6 +1007,22 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 	case FilterMatchesMsg:
 		m.filteredItems = filteredItems(msg)
+		m.updatePagination()
+		return m, nil
+
+	case filterDebounceMsg:
+		if msg.id == m.filterID {
+			m.updateFilterTargets()
+			return m, m.asyncFilter()
+		}
+		return m, nil
+
+	case asyncFilterMatchesMsg:
+		if msg.id == m.filterID && m.filterState != Unfiltered {
+			m.filteredItems = msg.matches
+			m.filterBase, m.filterBaseSet = msg.query, msg.matches
+			m.updatePagination()
+		}
 		return m, nil
 
 	case spinner.TickMsg:
@@ -841,6 +1032,13 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 		}
 
+		// Item spinners share a tick, which stops once none is left.
+		newItemSpinner, cmd := m.itemSpinner.Update(msg)
+		m.itemSpinner = newItemSpinner
+		if len(m.busy) > 0 {
+			cmds = append(cmds, cmd)
+		}
+
 	case statusMessageTimeoutMsg:
 		m.hideStatusMessage()
 	}
@@ -876,10 +1074,10 @@ func (m *Model) handleBrowsing(msg tea.Msg) tea.Cmd {
 			m.CursorDown()
 
 		case key.Matches(msg, m.KeyMap.PrevPage):
-			m.Paginator.PrevPage()
+			m.PrevPage()
 
 		case key.Matches(msg, m.KeyMap.NextPage):
-			m.Paginator.NextPage()
+			m.NextPage()
 
 		case key.Matches(msg, m.KeyMap.GoToStart):
 			m.GoToStart()
@@ -887,6 +1085,34 @@ func (m *Model) handleBrowsing(msg tea.Msg) tea.Cmd {
 		case key.Matches(msg, m.KeyMap.GoToEnd):
 			m.GoToEnd()
 
+		case key.Matches(msg, m.KeyMap.ToggleMark):
+			m.ToggleMark(m.Index())
+
+		case key.Matches(msg, m.KeyMap.MarkUp):
+			m.SetMarked(m.Index(), true)
+			m.CursorUp()
+			m.SetMarked(m.Index(), true)
+
+		case key.Matches(msg, m.KeyMap.MarkDown):
+			m.SetMarked(m.Index(), true)
+			m.CursorDown()
+			m.SetMarked(m.Index(), true)
+
+		case key.Matches(msg, m.KeyMap.MarkAll):
+			m.MarkAll()
+
+		case key.Matches(msg, m.KeyMap.UnmarkAll):
+			m.UnmarkAll()
+
+		case key.Matches(msg, m.KeyMap.InvertMarks):
+			m.InvertMarks()
+
+		case key.Matches(msg, m.KeyMap.MoveUp):
+			cmds = append(cmds, m.MoveItem(m.Index(), m.Index()-1))
+
+		case key.Matches(msg, m.KeyMap.MoveDown):
+			cmds = append(cmds, m.MoveItem(m.Index(), m.Index()+1))
+
 		case key.Matches(msg, m.KeyMap.Filter):
 			m.hideStatusMessage()
 			if m.FilterInput.Value() == "" {
@@ -906,12 +1132,17 @@ func (m *Model) handleBrowsing(msg tea.Msg) tea.Cmd {
 			m.Help.ShowAll = !m.Help.ShowAll
 			m.updatePagination()
 		}
+
+	case tea.MouseMsg:
+		m.handleMouse(msg)
+		cmds = append(cmds, m.handleDrag(msg))
 	}
 
 	cmd := m.delegate.Update(msg, m)
 	cmds = append(cmds, cmd)
 
 	m.cursor = clamp(m.cursor, 0, m.maxCursorIndex())
+	m.scrollToCursor()
 
 	return tea.Batch(cmds...)
 }
@@ -961,7 +1192,7 @@ func (m *Model) handleFiltering(msg tea.Msg) tea.Cmd {
 
 	// If the filtering input has changed, request updated filtering
 	if filterChanged {
-		cmds = append(cmds, filterItems(*m))
+		cmds = append(cmds, m.requestFilter())
 		m.KeyMap.AcceptWhileFiltering.SetEnabled(m.FilterInput.Value() != "")
 	}
 
@@ -977,6 +1208,7 @@ func (m Model) ShortHelp() []key.Binding {
 	kb := []key.Binding{
 		m.KeyMap.CursorUp,
 		m.KeyMap.CursorDown,
+		m.KeyMap.ToggleMark,
 	}
 
 	filtering := m.filterState == Filtering
@@ -1018,6 +1250,24 @@ func (m Model) FullHelp() [][]key.Binding {
 		m.KeyMap.GoToEnd,
 	}}
 
+	if m.multiSelect {
+		kb = append(kb, []key.Binding{
+			m.KeyMap.ToggleMark,
+			m.KeyMap.MarkUp,
+			m.KeyMap.MarkDown,
+			m.KeyMap.MarkAll,
+			m.KeyMap.UnmarkAll,
+			m.KeyMap.InvertMarks,
+		})
+	}
+
+	if m.reorderable {
+		kb = append(kb, []key.Binding{
+			m.KeyMap.MoveUp,
+			m.KeyMap.MoveDown,
+		})
+	}
+
 	filtering := m.filterState == Filtering
 
 	// If the delegate implements the help.KeyMap interface add full help
@@ -1162,6 +1412,9 @@ func (m Model) statusView() string {
 		} else {
 			status = itemsDisplay
 		}
+	} else if len(m.items) == 0 && m.placeholders > 0 {
+		// Not filtering: items are loading.
+		status = m.Styles.StatusEmpty.Render("Loading " + m.itemNamePlural + "…")
 	} else if len(m.items) == 0 {
 		// Not filtering: no items.
 		status = m.Styles.StatusEmpty.Render("No " + m.itemNamePlural)
@@ -1184,21 +1437,34 @@ func (m Model) statusView() string {
 		status += m.Styles.StatusBarFilterCount.Render(fmt.Sprintf("%d filtered", numFiltered))
 	}
 
+	if numMarked := len(m.marked); numMarked > 0 {
+		status += m.Styles.DividerDot.String()
+		status += m.Styles.StatusBarMarkedCount.Render(fmt.Sprintf("%d marked", numMarked))
+	}
+
 	return m.Styles.StatusBar.Render(status)
 }
 
 func (m Model) paginationView() string {
-	if m.Paginator.TotalPages < 2 { //nolint:mnd
-		return ""
-	}
+	var s string
+	if m.scrollMode {
+		s = m.scrollIndicatorView()
+		if s == "" {
+			return ""
+		}
+	} else {
+		if m.Paginator.TotalPages < 2 { //nolint:mnd
+			return ""
+		}
 
-	s := m.Paginator.View()
+		s = m.Paginator.View()
 
-	// If the dot pagination is wider than the width of the window
-	// use the arabic paginator.
-	if ansi.StringWidth(s) > m.width {
-		m.Paginator.Type = paginator.Arabic
-		s = m.Styles.ArabicPagination.Render(m.Paginator.View())
+		// If the dot pagination is wider than the width of the window
+		// use the arabic paginator.
+		if ansi.StringWidth(s) > m.width {
+			m.Paginator.Type = paginator.Arabic
+			s = m.Styles.ArabicPagination.Render(m.Paginator.View())
+		}
 	}
 
 	style := m.Styles.PaginationStyle
@@ -1219,19 +1485,24 @@ func (m Model) populatedView() string {
 		if m.filterState == Filtering {
 			return ""
 		}
+		if m.placeholders > 0 {
+			return m.placeholderView()
+		}
 		return m.Styles.NoItems.Render("No " + m.itemNamePlural + ".")
 	}
 
-	if len(items) > 0 {
-		start, end := m.Paginator.GetSliceBounds(len(items))
-		docs := items[start:end]
+	if m.scrollMode {
+		return m.scrollView(items)
+	}
 
-		for i, item := range docs {
-			m.delegate.Render(&b, m, i+start, item)
-			if i != len(docs)-1 {
-				fmt.Fprint(&b, strings.Repeat("\n", m.delegate.Spacing()+1))
-			}
-		}
+	start, end := m.pageBounds(m.Paginator.Page)
+	m.renderItems(&b, items, start, end, start)
+
+	// Pages of varying length are padded by the caller, and clipped in case
+	// a single item doesn't fit.
+	if m.pageStarts != nil {
+		lines := strings.Split(b.String(), "\n")
+		return strings.Join(lines[:min(len(lines), max(1, m.viewHeight))], "\n")
 	}
 
 	// If there aren't enough items to fill up this page (always the last page)
@@ -1257,6 +1528,189 @@ func (m Model) spinnerView() string {
 	return m.spinner.View()
 }
 
+// requestFilter returns a command filtering the items with the current filter
+// value. With AsyncFilter set, any query still in flight is cancelled and the
+// new one is debounced.
+func (m *Model) requestFilter() tea.Cmd {
+	m.updateFilterTargets()
+	if !m.AsyncFilter {
+		return filterItems(*m)
+	}
+
+	m.cancelFilter()
+	m.filterCtx, m.filterCancel = context.WithCancel(context.Background())
+
+	if m.FilterDebounce <= 0 {
+		return m.asyncFilter()
+	}
+	id := m.filterID
+	return tea.Tick(m.FilterDebounce, func(time.Time) tea.Msg {
+		return filterDebounceMsg{id: id}
+	})
+}
+
+// cancelFilter cancels the asynchronous filter query in flight, if any, and
+// makes sure its results are discarded should they arrive anyway.
+func (m *Model) cancelFilter() {
+	if m.filterCancel != nil {
+		m.filterCancel()
+		m.filterCancel = nil
+	}
+	m.filterID++
+}
+
+// resetFilterBase forgets the matches used for incremental filtering and the
+// cached filter values, e.g. because the items changed.
+func (m *Model) resetFilterBase() {
+	m.filterBase, m.filterBaseSet = "", nil
+	m.filterTargets = nil
+}
+
+// updateFilterTargets caches the filter values of the items, unless they're
+// cached already or ItemFilter is used instead.
+func (m *Model) updateFilterTargets() {
+	if m.ItemFilter != nil || len(m.filterTargets) == len(m.items) {
+		return
+	}
+	m.filterTargets = make([]string, len(m.items))
+	for i, item := range m.items {
+		m.filterTargets[i] = item.FilterValue()
+	}
+}
+
+// asyncFilter returns a command running the latest filter query. The query
+// is abandoned as soon as it's cancelled, while it's waiting to run or
+// between the chunks of items it ranks.
+func (m Model) asyncFilter() tea.Cmd {
+	ctx, id, query := m.filterCtx, m.filterID, m.FilterInput.Value()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var base filteredItems
+	if m.IncrementalFilter && m.ItemFilter == nil &&
+		m.filterBase != "" && strings.HasPrefix(query, m.filterBase) {
+		base = m.filterBaseSet
+	}
+
+	return func() tea.Msg {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if query == "" {
+			return asyncFilterMatchesMsg{id: id, matches: m.itemsAsFilterItems()}
+		}
+
+		indices := make([]int, 0, len(m.items))
+		if base != nil {
+			for _, c := range base {
+				indices = append(indices, c.index)
+			}
+			sort.Ints(indices)
+		} else {
+			for i := range m.items {
+				indices = append(indices, i)
+			}
+		}
+
+		ranks, ok := m.rankChunked(ctx, query, indices)
+		if !ok {
+			return nil
+		}
+		matches := make(filteredItems, len(ranks))
+		for i, r := range ranks {
+			matches[i] = filteredItem{
+				index:   r.Index,
+				item:    m.items[r.Index],
+				matches: r.MatchedIndexes,
+			}
+		}
+
+		matches = groupMatches(m.items, matches)
+		if ctx.Err() != nil {
+			return nil
+		}
+		return asyncFilterMatchesMsg{id: id, query: query, matches: matches}
+	}
+}
+
+// filterChunkSize is the number of items asynchronous filtering ranks at a
+// time, checking whether the query was cancelled in between.
+const filterChunkSize = 1000
+
+// rankChunked ranks the items at the given ascending indices in chunks. It
+// returns false as soon as ctx is cancelled. The returned ranks hold indices
+// into the items.
+func (m Model) rankChunked(ctx context.Context, term string, indices []int) ([]Rank, bool) {
+	var (
+		ranks  []Rank
+		chunks int
+	)
+	for start := 0; start < len(indices); start += filterChunkSize {
+		if ctx.Err() != nil {
+			return nil, false
+		}
+		chunk := indices[start:min(start+filterChunkSize, len(indices))]
+		chunkRanks := m.rankIndices(term, chunk)
+		if len(chunkRanks) > 0 {
+			chunks++
+		}
+		for _, r := range chunkRanks {
+			ranks = append(ranks, Rank{Index: chunk[r.Index], MatchedIndexes: r.MatchedIndexes})
+		}
+	}
+	if chunks <= 1 {
+		return ranks, ctx.Err() == nil
+	}
+
+	// Ranks are only ordered within their chunk, so rank the matches of all
+	// chunks together.
+	matched := make([]int, len(ranks))
+	for i, r := range ranks {
+		matched[i] = r.Index
+	}
+	sort.Ints(matched)
+	ranks = m.rankIndices(term, matched)
+	for i := range ranks {
+		ranks[i].Index = matched[ranks[i].Index]
+	}
+	return ranks, ctx.Err() == nil
+}
+
+// rankIndices ranks the items at the given ascending indices. The returned
+// ranks hold indices into indices. Consecutive indices are ranked without
+// copying the items or their cached filter values.
+func (m Model) rankIndices(term string, indices []int) []Rank {
+	if len(indices) == 0 {
+		return nil
+	}
+	if first, last := indices[0], indices[len(indices)-1]; last-first == len(indices)-1 {
+		return m.rank(term, m.items[first:last+1], m.cachedTargets(first, last+1))
+	}
+
+	items := make([]Item, len(indices))
+	for i, j := range indices {
+		items[i] = m.items[j]
+	}
+	var targets []string
+	if all := m.cachedTargets(0, len(m.items)); all != nil {
+		targets = make([]string, len(indices))
+		for i, j := range indices {
+			targets[i] = all[j]
+		}
+	}
+	return m.rank(term, items, targets)
+}
+
+// cachedTargets returns the cached filter values of the items from start up
+// to end, or nil if they aren't cached.
+func (m Model) cachedTargets(start, end int) []string {
+	if len(m.filterTargets) != len(m.items) {
+		return nil
+	}
+	return m.filterTargets[start:end]
+}
+
 func filterItems(m Model) tea.Cmd {
 	return func() tea.Msg {
 		if m.FilterInput.Value() == "" || m.filterState == Unfiltered {
@@ -1264,14 +1718,9 @@ func filterItems(m Model) tea.Cmd {
 		}
 
 		items := m.items
-		targets := make([]string, len(items))
-
-		for i, t := range items {
-			targets[i] = t.FilterValue()
-		}
 
 		filterMatches := []filteredItem{}
-		for _, r := range m.Filter(m.FilterInput.Value(), targets) {
+		for _, r := range m.rank(m.FilterInput.Value(), items, m.cachedTargets(0, len(items))) {
 			filterMatches = append(filterMatches, filteredItem{
 				index:   r.Index,
 				item:    items[r.Index],
@@ -1279,10 +1728,26 @@ func filterItems(m Model) tea.Cmd {
 			})
 		}
 
-		return FilterMatchesMsg(filterMatches)
+		return FilterMatchesMsg(groupMatches(items, filterMatches))
 	}
 }
 
+// rank filters the given items with ItemFilter or, if it's not set, Filter.
+// targets holds the filter values of the items, if they're cached.
+func (m Model) rank(term string, items []Item, targets []string) []Rank {
+	if m.ItemFilter != nil {
+		return m.ItemFilter(term, items)
+	}
+
+	if targets == nil {
+		targets = make([]string, len(items))
+		for i, t := range items {
+			targets[i] = t.FilterValue()
+		}
+	}
+	return m.Filter(term, targets)
+}
+
 func insertItemIntoSlice(items []Item, item Item, index int) []Item {
 	if items == nil {
 		return []Item{item}
@@ -1309,6 +1774,44 @@ func removeItemFromSlice(i []Item, index int) []Item {
 	return i[:len(i)-1]
 }
 
+// shiftItemState moves the per-item state, such as marks, at or after the
+// given index of the unfiltered list by delta, dropping the state at index
+// when an item is removed.
+func (m *Model) shiftItemState(index, delta int) {
+	m.remapItemState(func(i int) int {
+		switch {
+		case i < index:
+			return i
+		case delta < 0 && i == index:
+			return -1
+		}
+		return i + delta
+	})
+}
+
+// remapItemState moves the per-item state, such as marks, to the indices
+// returned by f. State mapped to a negative index is dropped.
+func (m *Model) remapItemState(f func(int) int) {
+	m.marked = remapIndices(m.marked, f)
+	m.badges = remapIndices(m.badges, f)
+	m.busy = remapIndices(m.busy, f)
+}
+
+// remapIndices returns a copy of the given map with its keys mapped by f.
+// Keys mapped to a negative index are dropped.
+func remapIndices[V any](indices map[int]V, f func(int) int) map[int]V {
+	if len(indices) == 0 {
+		return indices
+	}
+	remapped := make(map[int]V, len(indices))
+	for i, v := range indices {
+		if j := f(i); j >= 0 {
+			remapped[j] = v
+		}
+	}
+	return remapped
+}
+
 func removeFilterMatchFromSlice(i []filteredItem, index int) []filteredItem {
 	if index >= len(i) {
 		return i // noop
@@ -1,13 +1,18 @@
 package list
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
 )
 
 type item string
@@ -135,3 +140,615 @@ func TestSetFilterState(t *testing.T) {
 		t.Fatalf("Error: expected view to contain '%s'", expected)
 	}
 }
+
+func TestAsyncFilter(t *testing.T) {
+	tc := []Item{item("foo"), item("bar"), item("baz")}
+
+	list := New(tc, itemDelegate{}, 10, 10)
+	list.AsyncFilter = true
+	list.IncrementalFilter = true
+	list.SetFilterState(Filtering)
+
+	typeRune := func(r rune) tea.Cmd {
+		var cmd tea.Cmd
+		list, cmd = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		return cmd
+	}
+	run := func(cmd tea.Cmd) {
+		for _, msg := range flatten(cmd) {
+			list, _ = list.Update(msg)
+		}
+	}
+
+	stale := typeRune('b')
+	fresh := typeRune('a')
+	run(fresh)
+	run(stale)
+
+	expected := []Item{item("bar"), item("baz")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("expected %v, got %v", expected, list.VisibleItems())
+	}
+
+	// Narrowing the query only searches the previous matches, so dropping
+	// "bar" from them hides it from the narrowed results.
+	list.filterBaseSet = list.filterBaseSet[1:]
+	run(typeRune('r'))
+	if len(list.VisibleItems()) != 0 {
+		t.Fatalf("expected narrowed query to search previous matches, got %v", list.VisibleItems())
+	}
+}
+
+func TestAsyncFilterDebounce(t *testing.T) {
+	tc := []Item{item("foo"), item("bar"), item("baz")}
+
+	list := New(tc, itemDelegate{}, 10, 10)
+	list.AsyncFilter = true
+	list.FilterDebounce = time.Millisecond
+	list.SetFilterState(Filtering)
+
+	var first, second tea.Cmd
+	list, first = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	list, second = list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+
+	for _, msg := range flatten(first) {
+		if _, ok := msg.(filterDebounceMsg); !ok {
+			continue
+		}
+		var cmd tea.Cmd
+		list, cmd = list.Update(msg)
+		if cmd != nil {
+			t.Fatal("expected superseded debounce to be ignored")
+		}
+	}
+
+	for _, msg := range flatten(second) {
+		var cmd tea.Cmd
+		list, cmd = list.Update(msg)
+		if _, ok := msg.(filterDebounceMsg); ok {
+			list, _ = list.Update(cmd())
+		}
+	}
+
+	expected := []Item{item("foo")}
+	if !reflect.DeepEqual(list.VisibleItems(), expected) {
+		t.Fatalf("expected %v, got %v", expected, list.VisibleItems())
+	}
+}
+
+func TestAsyncFilterChunks(t *testing.T) {
+	// Spread matches of differing quality over several chunks, so that the
+	// chunks' ranks need merging.
+	items := make([]Item, 3*filterChunkSize)
+	targets := make([]string, len(items))
+	for i := range items {
+		v := fmt.Sprintf("item %d", i)
+		if i%700 == 0 {
+			v = fmt.Sprintf("x%dzzz", i)
+		}
+		if i%1100 == 0 {
+			v = fmt.Sprintf("xz%d", i)
+		}
+		items[i], targets[i] = item(v), v
+	}
+
+	list := New(items, itemDelegate{}, 10, 10)
+	list.updateFilterTargets()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	indices := make([]int, len(items))
+	for i := range indices {
+		indices[i] = i
+	}
+	got, ok := list.rankChunked(ctx, "xz", indices)
+	if want := DefaultFilter("xz", targets); !ok || !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	cancel()
+	if _, ok := list.rankChunked(ctx, "xz", indices); ok {
+		t.Fatal("expected cancelled query to be abandoned")
+	}
+}
+
+// flatten runs cmd and returns the messages it produced, unpacking batches.
+func flatten(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for _, c := range batch {
+		msgs = append(msgs, flatten(c)...)
+	}
+	return msgs
+}
+
+func TestMultiSelect(t *testing.T) {
+	tc := []Item{item("foo"), item("bar"), item("baz"), item("qux")}
+
+	list := New(tc, itemDelegate{}, 10, 20)
+	list.SetMultiSelect(true)
+
+	press := func(keys ...string) {
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			if k == " " {
+				msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(k)}
+			}
+			list, _ = list.Update(msg)
+		}
+	}
+
+	press(" ", "J")
+	expected := []Item{item("foo"), item("bar")}
+	if !reflect.DeepEqual(list.MarkedItems(), expected) {
+		t.Fatalf("expected %v marked, got %v", expected, list.MarkedItems())
+	}
+	if !strings.Contains(list.View(), "2 marked") {
+		t.Fatal("expected status bar to show the marked count")
+	}
+
+	// Marks survive filtering and bulk actions only affect visible items.
+	list.SetFilterText("ba")
+	if !list.IsMarked(0) || list.IsMarked(1) {
+		t.Fatal("expected marks to follow the items when filtered")
+	}
+	press("i")
+	expected = []Item{item("foo"), item("baz")}
+	if !reflect.DeepEqual(list.MarkedItems(), expected) {
+		t.Fatalf("expected %v marked, got %v", expected, list.MarkedItems())
+	}
+
+	list.ResetFilter()
+	list.InsertItem(0, item("new"))
+	list.RemoveItem(2)
+	if got := list.MarkedIndices(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("expected marks to shift with the items, got %v", got)
+	}
+
+	press("n")
+	if len(list.MarkedItems()) != 0 {
+		t.Fatalf("expected no marked items, got %v", list.MarkedItems())
+	}
+
+	list.SetMultiSelect(false)
+	press("a")
+	if len(list.MarkedItems()) != 0 {
+		t.Fatal("expected marking to be disabled")
+	}
+}
+
+type groupedItem struct{ group, name string }
+
+func (i groupedItem) FilterValue() string { return i.name }
+func (i groupedItem) Group() string       { return i.group }
+
+type groupedDelegate struct{ itemDelegate }
+
+func (d groupedDelegate) Render(w io.Writer, m Model, index int, listItem Item) {
+	fmt.Fprint(w, listItem.(groupedItem).name)
+}
+
+func TestSections(t *testing.T) {
+	tc := []Item{
+		groupedItem{"fruits", "apple"},
+		groupedItem{"fruits", "banana"},
+		groupedItem{"fruits", "cherry"},
+		groupedItem{"vegetables", "carrot"},
+		groupedItem{"vegetables", "leek"},
+	}
+
+	list := New(tc, groupedDelegate{}, 20, 3)
+	list.SetShowTitle(false)
+	list.SetShowFilter(false)
+	list.SetShowStatusBar(false)
+	list.SetShowPagination(false)
+	list.SetShowHelp(false)
+	list.Styles.SectionHeader = list.Styles.SectionHeader.UnsetPadding()
+
+	pages := []string{
+		"fruits\napple\nbanana",
+		"fruits\ncherry\n",
+		"vegetables\ncarrot\nleek",
+	}
+	if list.Paginator.TotalPages != len(pages) {
+		t.Fatalf("expected %d pages, got %d", len(pages), list.Paginator.TotalPages)
+	}
+	for i, expected := range pages {
+		list.Paginator.Page = i
+		if got := plainView(list); got != expected {
+			t.Fatalf("page %d: expected %q, got %q", i, expected, got)
+		}
+	}
+
+	list.ResetSelected()
+	for range 3 {
+		list.CursorDown()
+	}
+	if list.Index() != 3 || list.Paginator.Page != 2 || list.Cursor() != 0 {
+		t.Fatalf("expected cursor on carrot, got index %d on page %d", list.Index(), list.Paginator.Page)
+	}
+
+	list.SetFilterText("leek")
+	if got := plainView(list); got != "vegetables\nleek\n" {
+		t.Fatalf("expected empty sections to be hidden, got %q", got)
+	}
+}
+
+// plainView renders the list without styling and trailing whitespace.
+func plainView(m Model) string {
+	lines := strings.Split(ansi.Strip(m.View()), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestScrollMode(t *testing.T) {
+	var tc []Item
+	for _, name := range strings.Split("abcdefghij", "") {
+		tc = append(tc, groupedItem{name: name})
+	}
+
+	list := New(tc, groupedDelegate{}, 20, 4)
+	list.SetShowTitle(false)
+	list.SetShowFilter(false)
+	list.SetShowStatusBar(false)
+	list.SetShowPagination(false)
+	list.SetShowHelp(false)
+	list.ScrollMargin = 1
+	list.SetScrollMode(true)
+
+	assertView := func(expected string) {
+		t.Helper()
+		if got := plainView(list); got != expected {
+			t.Fatalf("expected %q, got %q", expected, got)
+		}
+	}
+
+	assertView("a\nb\nc\nd")
+
+	list.CursorDown()
+	list.CursorDown()
+	assertView("a\nb\nc\nd")
+
+	// Keep an item below the cursor in view.
+	list.CursorDown()
+	assertView("b\nc\nd\ne")
+
+	// Scrolling drags the cursor along, keeping the margin.
+	list, _ = list.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	assertView("e\nf\ng\nh")
+	if list.Index() != 5 {
+		t.Fatalf("expected cursor on f, got %d", list.Index())
+	}
+
+	list.GoToEnd()
+	assertView("g\nh\ni\nj")
+
+	list.SetShowPagination(true)
+	if !strings.Contains(plainView(list), "↑ 10/10") {
+		t.Fatalf("expected scroll indicator, got %q", plainView(list))
+	}
+}
+
+func TestScrollModeStickyHeaders(t *testing.T) {
+	tc := []Item{
+		groupedItem{"fruits", "apple"},
+		groupedItem{"fruits", "banana"},
+		groupedItem{"fruits", "cherry"},
+		groupedItem{"fruits", "date"},
+		groupedItem{"vegetables", "carrot"},
+		groupedItem{"vegetables", "leek"},
+	}
+
+	list := New(tc, groupedDelegate{}, 20, 3)
+	list.SetShowTitle(false)
+	list.SetShowFilter(false)
+	list.SetShowStatusBar(false)
+	list.SetShowPagination(false)
+	list.SetShowHelp(false)
+	list.Styles.SectionHeader = list.Styles.SectionHeader.UnsetPadding()
+	list.SetScrollMode(true)
+
+	list.Select(3)
+	if got := plainView(list); got != "fruits\ncherry\ndate" {
+		t.Fatalf("expected sticky header, got %q", got)
+	}
+
+	// The sticky header covers the top line until the next section's header
+	// reaches it.
+	list.CursorDown()
+	if got := plainView(list); got != "fruits\nvegetables\ncarrot" {
+		t.Fatalf("expected sticky header above inline header, got %q", got)
+	}
+
+	list.CursorDown()
+	if got := plainView(list); got != "vegetables\ncarrot\nleek" {
+		t.Fatalf("expected inline header at the top, got %q", got)
+	}
+}
+
+// tallDelegate renders items as many lines tall as their names are long.
+type tallDelegate struct{ groupedDelegate }
+
+func (d tallDelegate) HeightOf(listItem Item) int {
+	return len(listItem.(groupedItem).name)
+}
+
+func (d tallDelegate) Render(w io.Writer, m Model, index int, listItem Item) {
+	name := listItem.(groupedItem).name
+	fmt.Fprint(w, strings.TrimSuffix(strings.Repeat(name[:1]+"\n", len(name)), "\n"))
+}
+
+func TestVariableHeight(t *testing.T) {
+	tc := []Item{
+		groupedItem{name: "a"},
+		groupedItem{name: "bb"},
+		groupedItem{name: "ccc"},
+		groupedItem{name: "d"},
+		groupedItem{name: "eeeeee"},
+	}
+
+	list := New(tc, tallDelegate{}, 20, 4)
+	list.SetShowTitle(false)
+	list.SetShowFilter(false)
+	list.SetShowStatusBar(false)
+	list.SetShowPagination(false)
+	list.SetShowHelp(false)
+
+	pages := []string{"a\nb\nb\n", "c\nc\nc\nd", "e\ne\ne\ne"}
+	if list.Paginator.TotalPages != len(pages) {
+		t.Fatalf("expected %d pages, got %d", len(pages), list.Paginator.TotalPages)
+	}
+	for i, expected := range pages {
+		list.Paginator.Page = i
+		if got := plainView(list); got != expected {
+			t.Fatalf("page %d: expected %q, got %q", i, expected, got)
+		}
+	}
+
+	list.Select(3)
+	if list.Paginator.Page != 1 || list.Cursor() != 1 {
+		t.Fatalf("expected cursor on the second item of page 1, got %d on page %d", list.Cursor(), list.Paginator.Page)
+	}
+
+	list.SetScrollMode(true)
+	list.GoToStart()
+	list.CursorDown()
+	list.CursorDown()
+	if got := plainView(list); got != "b\nc\nc\nc" {
+		t.Fatalf("expected view to scroll to ccc, got %q", got)
+	}
+}
+
+func TestReorder(t *testing.T) {
+	tc := []Item{
+		groupedItem{name: "a"},
+		groupedItem{name: "b"},
+		groupedItem{name: "c"},
+		groupedItem{name: "d"},
+	}
+
+	list := New(tc, groupedDelegate{}, 20, 4)
+	list.SetShowTitle(false)
+	list.SetShowFilter(false)
+	list.SetShowStatusBar(false)
+	list.SetShowPagination(false)
+	list.SetShowHelp(false)
+	list.SetMultiSelect(true)
+	list.SetReorderable(true)
+
+	names := func() string {
+		var s string
+		for _, i := range list.Items() {
+			s += i.(groupedItem).name
+		}
+		return s
+	}
+
+	list.Select(1)
+	list.ToggleMark(1)
+	var cmd tea.Cmd
+	list, cmd = list.Update(tea.KeyMsg{Type: tea.KeyDown, Alt: true})
+	if names() != "acbd" || list.Index() != 2 || !list.IsMarked(2) {
+		t.Fatalf("expected b to move down with its mark, got %s at %d", names(), list.Index())
+	}
+	if msg := cmd(); !reflect.DeepEqual(msg, ItemsReorderedMsg{From: 1, To: 2}) {
+		t.Fatalf("unexpected message %#v", msg)
+	}
+
+	// Drag d to the top.
+	list.SetDragAndDrop(true)
+	list.SetPosition(0, 2)
+	mouse := func(action tea.MouseAction, y int) tea.Cmd {
+		list, cmd = list.Update(tea.MouseMsg{X: 1, Y: y, Action: action, Button: tea.MouseButtonLeft})
+		return cmd
+	}
+	mouse(tea.MouseActionPress, 5)
+	mouse(tea.MouseActionMotion, 3)
+	mouse(tea.MouseActionMotion, 2)
+	cmd = mouse(tea.MouseActionRelease, 2)
+	if names() != "dacb" || list.Index() != 0 {
+		t.Fatalf("expected d to be dragged to the top, got %s at %d", names(), list.Index())
+	}
+	if msg := cmd(); !reflect.DeepEqual(msg, ItemsReorderedMsg{From: 3, To: 0}) {
+		t.Fatalf("unexpected message %#v", msg)
+	}
+
+	list.SetFilterText("a")
+	if list.KeyMap.MoveUp.Enabled() {
+		t.Fatal("expected moving to be disabled while filtered")
+	}
+}
+
+type fieldItem struct{ name, color string }
+
+func (i fieldItem) FilterValue() string { return i.name }
+func (i fieldItem) FilterFields() map[string]string {
+	return map[string]string{"color": i.color}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected Query
+	}{
+		{"", nil},
+		{"apple pie", Query{{{Value: "apple"}, {Value: "pie"}}}},
+		{`"green apple" -pie`, Query{{{Value: "green apple", Phrase: true}, {Value: "pie", Negate: true}}}},
+		{`-color:"dark red"`, Query{{{Field: "color", Value: "dark red", Phrase: true, Negate: true}}}},
+		{"a OR b | c", Query{{{Value: "a"}}, {{Value: "b"}}, {{Value: "c"}}}},
+		{`"a:b" "OR"`, Query{{{Value: "a:b", Phrase: true}, {Value: "OR", Phrase: true}}}},
+	}
+	for _, tt := range tests {
+		if got := ParseQuery(tt.query); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%q: expected %+v, got %+v", tt.query, tt.expected, got)
+		}
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	items := []Item{
+		fieldItem{"green apple", "green"},
+		fieldItem{"red apple", "red"},
+		fieldItem{"pear", "green"},
+		item("plum"),
+	}
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"apple", []int{0, 1}},
+		{`"red apple"`, []int{1}},
+		{"apple -red", []int{0}},
+		{"color:green", []int{0, 2}},
+		{"-color:green", []int{1, 3}},
+		{"pear OR plum", []int{2, 3}},
+		{`"app le"`, nil},
+	}
+	for _, tt := range tests {
+		var got []int
+		for _, r := range QueryFilter(tt.query, items) {
+			got = append(got, r.Index)
+		}
+		sort.Ints(got)
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%q: expected %v, got %v", tt.query, tt.expected, got)
+		}
+	}
+
+	list := New(items, itemDelegate{}, 20, 10)
+	list.ItemFilter = QueryFilter
+	list.SetFilterText(`"apple" color:red`)
+	if got := list.VisibleItems(); !reflect.DeepEqual(got, []Item{items[1]}) {
+		t.Fatalf("expected red apple, got %v", got)
+	}
+	if got := list.MatchesForItem(0); !reflect.DeepEqual(got, []int{4, 5, 6, 7, 8}) {
+		t.Fatalf("expected phrase to be highlighted, got %v", got)
+	}
+}
+
+type titledItem struct{ title, desc string }
+
+func (i titledItem) FilterValue() string { return i.title }
+func (i titledItem) Title() string       { return i.title }
+func (i titledItem) Description() string { return i.desc }
+
+func TestItemStatus(t *testing.T) {
+	tc := []Item{titledItem{"deploy", "prod"}, titledItem{"download", "file"}}
+
+	d := NewDefaultDelegate()
+	d.ShowDescription = false
+	list := New(tc, d, 40, 20)
+
+	list.SetItemBadge(1, "42%")
+	if !strings.Contains(ansi.Strip(list.View()), "download  42% ") {
+		t.Fatalf("expected badge after the title, got %q", ansi.Strip(list.View()))
+	}
+
+	first := list.StartItemSpinner(0)
+	if first == nil || list.StartItemSpinner(1) != nil {
+		t.Fatal("expected item spinners to share a single tick")
+	}
+	frame := ansi.Strip(list.ItemSpinnerView())
+	if !strings.Contains(ansi.Strip(list.View()), frame+" deploy") {
+		t.Fatalf("expected spinner before the title, got %q", ansi.Strip(list.View()))
+	}
+
+	list, next := list.Update(first())
+	if next == nil {
+		t.Fatal("expected the tick to continue while items are busy")
+	}
+	list.StopItemSpinner(0)
+	list.StopItemSpinner(1)
+	if _, next = list.Update(next()); next != nil {
+		t.Fatal("expected the tick to stop once no item is busy")
+	}
+
+	// Per-item state follows its item.
+	list.InsertItem(0, titledItem{"build", "ci"})
+	if list.ItemBadge(2) != "42%" {
+		t.Fatal("expected the badge to move along with its item")
+	}
+
+	list.SetItems(nil)
+	list.SetPlaceholders(3)
+	view := ansi.Strip(list.View())
+	if !strings.Contains(view, "Loading items…") || strings.Count(view, "  ░") != 3 {
+		t.Fatalf("expected three placeholders, got %q", view)
+	}
+
+	list.SetItems(tc)
+	if list.Placeholders() != 0 || list.ItemBadge(1) != "" {
+		t.Fatal("expected SetItems to clear placeholders and badges")
+	}
+}
+
+type idItem struct{ id, name string }
+
+func (i idItem) FilterValue() string { return i.name }
+func (i idItem) ID() string          { return i.id }
+
+func TestState(t *testing.T) {
+	tc := []Item{idItem{"1", "apple"}, idItem{"2", "apricot"}, idItem{"3", "avocado"}, idItem{"4", "banana"}}
+
+	list := New(tc, itemDelegate{}, 20, 20)
+	list.SetFilterText("a")
+	list.Select(2)
+	list.Help.ShowAll = true
+
+	state := list.State()
+	b, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reload the items in a different order and come back.
+	restored := New(nil, itemDelegate{}, 20, 20)
+	restored.SetItems([]Item{tc[3], tc[2], tc[1], tc[0]})
+	var decoded State
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	restored.RestoreState(decoded)
+
+	if restored.FilterState() != FilterApplied || restored.FilterValue() != "a" {
+		t.Fatalf("expected filter to be restored, got %q (%s)", restored.FilterValue(), restored.FilterState())
+	}
+	if restored.SelectedItem() != list.SelectedItem() {
+		t.Fatalf("expected %v to be selected, got %v", list.SelectedItem(), restored.SelectedItem())
+	}
+	if !restored.Help.ShowAll {
+		t.Fatal("expected full help to be restored")
+	}
+	if got := restored.State(); got.SelectedID != state.SelectedID {
+		t.Fatalf("expected restored state to match, got %+v", got)
+	}
+}
@@ -0,0 +1,119 @@
+package list
+
+import "sort"
+
+// MultiSelect returns whether marking multiple items is enabled.
+func (m Model) MultiSelect() bool {
+	return m.multiSelect
+}
+
+// SetMultiSelect enables or disables marking multiple items. Disabling it
+// clears all marks.
+func (m *Model) SetMultiSelect(v bool) {
+	m.multiSelect = v
+	if !v {
+		m.marked = nil
+	}
+	m.updatePagination()
+	m.updateKeybindings()
+}
+
+// IsMarked returns whether the item at the given index of the visible items
+// is marked. This is the index passed to ItemDelegate.Render.
+func (m Model) IsMarked(index int) bool {
+	i, ok := m.globalIndexOf(index)
+	if !ok {
+		return false
+	}
+	_, ok = m.marked[i]
+	return ok
+}
+
+// SetMarked marks or unmarks the item at the given index of the visible
+// items. Marks are kept on the underlying items, so they survive filtering
+// and pagination.
+func (m *Model) SetMarked(index int, marked bool) {
+	i, ok := m.globalIndexOf(index)
+	if !ok {
+		return
+	}
+	if !marked {
+		delete(m.marked, i)
+		return
+	}
+	if m.marked == nil {
+		m.marked = make(map[int]struct{})
+	}
+	m.marked[i] = struct{}{}
+}
+
+// ToggleMark toggles the mark of the item at the given index of the visible
+// items.
+func (m *Model) ToggleMark(index int) {
+	m.SetMarked(index, !m.IsMarked(index))
+}
+
+// MarkRange marks the visible items between from and to, inclusive. The
+// bounds may be given in any order.
+func (m *Model) MarkRange(from, to int) {
+	if from > to {
+		from, to = to, from
+	}
+	n := len(m.VisibleItems())
+	for i := max(from, 0); i <= min(to, n-1); i++ {
+		m.SetMarked(i, true)
+	}
+}
+
+// MarkAll marks all visible items.
+func (m *Model) MarkAll() {
+	m.MarkRange(0, len(m.VisibleItems())-1)
+}
+
+// UnmarkAll clears all marks, including those of items hidden by the
+// current filter.
+func (m *Model) UnmarkAll() {
+	m.marked = nil
+}
+
+// InvertMarks toggles the mark of every visible item.
+func (m *Model) InvertMarks() {
+	for i := range m.VisibleItems() {
+		m.ToggleMark(i)
+	}
+}
+
+// MarkedItems returns the marked items in the order they appear in the
+// unfiltered list.
+func (m Model) MarkedItems() []Item {
+	indices := m.MarkedIndices()
+	items := make([]Item, len(indices))
+	for i, index := range indices {
+		items[i] = m.items[index]
+	}
+	return items
+}
+
+// MarkedIndices returns the indices of the marked items as stored in the
+// unfiltered list of items, in ascending order. These can be used with
+// SetItem().
+func (m Model) MarkedIndices() []int {
+	indices := make([]int, 0, len(m.marked))
+	for i := range m.marked {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// globalIndexOf maps an index of the visible items to an index in the
+// unfiltered list of items.
+func (m Model) globalIndexOf(index int) (int, bool) {
+	if m.filterState == Unfiltered {
+		return index, index >= 0 && index < len(m.items)
+	}
+	if index < 0 || index >= len(m.filteredItems) {
+		return 0, false
+	}
+	return m.filteredItems[index].index, true
+}
@@ -0,0 +1,303 @@
+package list
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sahilm/fuzzy"
+)
+
+// ItemFilterFunc takes a term and the items to search through and returns a
+// sorted list of ranks. Unlike FilterFunc it has access to the items
+// themselves, e.g. to match fields other than Item#FilterValue.
+type ItemFilterFunc func(string, []Item) []Rank
+
+// FilterFielder is an optional interface for items exposing named fields to
+// field:value qualifiers in QueryFilter.
+type FilterFielder interface {
+	Item
+
+	// FilterFields returns the values of the item's fields by name.
+	FilterFields() map[string]string
+}
+
+// QueryFilter filters items with a small query language:
+//
+//	apple              fuzzy-matches "apple" against FilterValue
+//	"green apple"      matches the exact phrase, ignoring case
+//	-apple             excludes items matching "apple"
+//	color:green        matches items whose "color" field contains "green"
+//	apple OR pear      matches items matching either side; "|" works too
+//
+// Terms separated by spaces must all match. Fields are provided by items
+// implementing FilterFielder, and values may be quoted. Matched characters
+// of FilterValue are reported for highlighting. Results are sorted by how
+// well they fuzzy-match.
+//
+// Since adding a negated term can bring back items, QueryFilter doesn't work
+// with Model.IncrementalFilter.
+func QueryFilter(query string, items []Item) []Rank {
+	q := ParseQuery(query)
+
+	type scored struct {
+		Rank
+		score int
+	}
+	var results []scored
+	for i, item := range items {
+		indexes, score, ok := q.Match(item)
+		if !ok {
+			continue
+		}
+		results = append(results, scored{
+			Rank:  Rank{Index: i, MatchedIndexes: indexes},
+			score: score,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	ranks := make([]Rank, len(results))
+	for i, r := range results {
+		ranks[i] = r.Rank
+	}
+	return ranks
+}
+
+// Query is a parsed QueryFilter query: alternatives, each made of terms
+// which must all match.
+type Query [][]QueryTerm
+
+// QueryTerm is a single term of a Query.
+type QueryTerm struct {
+	// Field is the name of the field to match for field:value qualifiers,
+	// or empty to match FilterValue.
+	Field string
+
+	// Value is the text to match.
+	Value string
+
+	// Phrase is set for quoted values, which must match exactly rather than
+	// fuzzily, ignoring case.
+	Phrase bool
+
+	// Negate is set for terms prefixed with "-", which must not match.
+	Negate bool
+}
+
+// ParseQuery parses a QueryFilter query. Unterminated quotes run to the end
+// of the query.
+func ParseQuery(s string) Query {
+	var (
+		q     Query
+		terms []QueryTerm
+	)
+	for _, tok := range tokenize(s) {
+		if tok.quoteAt < 0 && (tok.text == "OR" || tok.text == "|") {
+			if len(terms) > 0 {
+				q = append(q, terms)
+			}
+			terms = nil
+			continue
+		}
+
+		// Prefixes are only parsed outside of quotes.
+		var t QueryTerm
+		text, head := tok.text, tok.text
+		if tok.quoteAt >= 0 {
+			head = text[:tok.quoteAt]
+		}
+		if strings.HasPrefix(head, "-") && len(text) > 1 {
+			t.Negate, text, head = true, text[1:], head[1:]
+		}
+		if field, _, ok := strings.Cut(head, ":"); ok && field != "" {
+			t.Field, text, head = field, text[len(field)+1:], head[len(field)+1:]
+		}
+		t.Value, t.Phrase = text, tok.quoteAt >= 0 && head == ""
+
+		if t.Value == "" && t.Field == "" {
+			continue
+		}
+		terms = append(terms, t)
+	}
+	if len(terms) > 0 {
+		q = append(q, terms)
+	}
+	return q
+}
+
+// Match reports whether the item matches the query, along with the rune
+// indices of FilterValue matched by the first matching alternative and a
+// score for ranking.
+func (q Query) Match(item Item) (indexes []int, score int, ok bool) {
+	if len(q) == 0 {
+		return nil, 0, true
+	}
+	for _, terms := range q {
+		if indexes, score, ok = matchAll(terms, item); ok {
+			return indexes, score, true
+		}
+	}
+	return nil, 0, false
+}
+
+func matchAll(terms []QueryTerm, item Item) (indexes []int, score int, ok bool) {
+	seen := make(map[int]struct{})
+	for _, t := range terms {
+		idx, s, matched := t.match(item)
+		if matched == t.Negate {
+			return nil, 0, false
+		}
+		if t.Negate || t.Field != "" {
+			continue
+		}
+		score += s
+		for _, i := range idx {
+			if _, dup := seen[i]; !dup {
+				seen[i] = struct{}{}
+				indexes = append(indexes, i)
+			}
+		}
+	}
+	sort.Ints(indexes)
+	return indexes, score, true
+}
+
+// match returns whether the term matches the item, ignoring negation, with
+// the matched rune indices and score.
+func (t QueryTerm) match(item Item) (indexes []int, score int, ok bool) {
+	value := item.FilterValue()
+	if t.Field != "" {
+		f, isFielder := item.(FilterFielder)
+		if !isFielder {
+			return nil, 0, false
+		}
+		value, ok = lookupField(f.FilterFields(), t.Field)
+		if !ok {
+			return nil, 0, false
+		}
+		if t.Value == "" {
+			return nil, 0, true
+		}
+		_, ok = indexFold(value, t.Value)
+		return nil, 0, ok
+	}
+
+	if t.Phrase {
+		start, ok := indexFold(value, t.Value)
+		if !ok {
+			return nil, 0, false
+		}
+		n := utf8.RuneCountInString(t.Value)
+		indexes = make([]int, n)
+		for i := range indexes {
+			indexes[i] = start + i
+		}
+		return indexes, n, true
+	}
+
+	matches := fuzzy.Find(t.Value, []string{value})
+	if len(matches) == 0 {
+		return nil, 0, false
+	}
+	return runeIndexes(value, matches[0].MatchedIndexes), matches[0].Score, true
+}
+
+// lookupField returns the value of the named field, ignoring case.
+func lookupField(fields map[string]string, name string) (string, bool) {
+	if v, ok := fields[name]; ok {
+		return v, true
+	}
+	for k, v := range fields {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// indexFold returns the rune index of the first occurrence of substr in s,
+// ignoring case.
+func indexFold(s, substr string) (int, bool) {
+	haystack, needle := foldRunes(s), foldRunes(substr)
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		if runesEqual(haystack[i:i+len(needle)], needle) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func foldRunes(s string) []rune {
+	r := []rune(s)
+	for i := range r {
+		r[i] = unicode.ToLower(r[i])
+	}
+	return r
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// runeIndexes converts byte indices of s into rune indices.
+func runeIndexes(s string, byteIndexes []int) []int {
+	indexes := make([]int, 0, len(byteIndexes))
+	for _, b := range byteIndexes {
+		indexes = append(indexes, utf8.RuneCountInString(s[:b]))
+	}
+	return indexes
+}
+
+type token struct {
+	text string
+
+	// quoteAt is the index in text where quoted text starts, or -1.
+	quoteAt int
+}
+
+// tokenize splits a query into whitespace-separated tokens, keeping quoted
+// text together. Quotes may follow a prefix such as "-" or "field:".
+func tokenize(s string) []token {
+	var (
+		tokens []token
+		b      strings.Builder
+		inWord bool
+		quote  bool
+	)
+	quoteAt := -1
+	flush := func() {
+		if inWord {
+			tokens = append(tokens, token{text: b.String(), quoteAt: quoteAt})
+		}
+		b.Reset()
+		inWord, quoteAt = false, -1
+	}
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			if !quote && quoteAt < 0 {
+				quoteAt = b.Len()
+			}
+			quote = !quote
+			inWord = true
+		case unicode.IsSpace(r) && !quote:
+			flush()
+		default:
+			b.WriteRune(r)
+			inWord = true
+		}
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,206 @@
+package list
+
+import (
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ItemsReorderedMsg is sent when the user moves an item to another position,
+// so the new order can be persisted. From and To are indices in the
+// unfiltered list of items.
+type ItemsReorderedMsg struct {
+	From int
+	To   int
+}
+
+// Reorderable returns whether the user can move items.
+func (m Model) Reorderable() bool {
+	return m.reorderable
+}
+
+// SetReorderable allows or disallows the user to move items with the
+// MoveUp and MoveDown keybindings. Items can't be moved while a filter is
+// applied.
+func (m *Model) SetReorderable(v bool) {
+	m.reorderable = v
+	m.updateKeybindings()
+}
+
+// DragAndDrop returns whether items can be moved with the mouse.
+func (m Model) DragAndDrop() bool {
+	return m.dragAndDrop
+}
+
+// SetDragAndDrop allows or disallows the user to select items by clicking
+// them and, if the list is reorderable, to move them by dragging. The list
+// must be told where it's rendered with SetPosition unless it's at the top
+// left corner of the terminal.
+func (m *Model) SetDragAndDrop(v bool) {
+	m.dragAndDrop = v
+	m.dragging = false
+}
+
+// SetPosition sets the position of the top left corner of the list in the
+// terminal, used to find the item under the mouse.
+func (m *Model) SetPosition(x, y int) {
+	m.posX, m.posY = x, y
+}
+
+// MoveItem moves the item at the given index to another index. Both are
+// indices in the unfiltered list of items. Marks and other per-item state
+// move along with the item and the cursor follows it when the list isn't
+// filtered. The returned command sends an ItemsReorderedMsg.
+func (m *Model) MoveItem(from, to int) tea.Cmd {
+	if !m.moveItem(from, to) {
+		return nil
+	}
+	return reorderedCmd(from, to)
+}
+
+// moveItem moves an item without reporting it, returning whether it moved.
+func (m *Model) moveItem(from, to int) bool {
+	n := len(m.items)
+	if from < 0 || from >= n || to < 0 || to >= n || from == to {
+		return false
+	}
+
+	item := m.items[from]
+	if from < to {
+		copy(m.items[from:to], m.items[from+1:to+1])
+	} else {
+		copy(m.items[to+1:from+1], m.items[to:from])
+	}
+	m.items[to] = item
+
+	m.remapItemState(func(i int) int {
+		return movedIndex(i, from, to)
+	})
+
+	m.resetFilterBase()
+	if m.filterState != Unfiltered {
+		// Keep the filtered items pointing at the right indices.
+		for i, fi := range m.filteredItems {
+			m.filteredItems[i].index = movedIndex(fi.index, from, to)
+		}
+		m.updatePagination()
+		return true
+	}
+
+	m.updatePagination()
+	m.Select(to)
+	return true
+}
+
+// canMove returns whether the user can currently move items.
+func (m Model) canMove() bool {
+	return m.reorderable && m.filterState == Unfiltered && len(m.items) > 1
+}
+
+// handleDrag selects the clicked item and, when reorderable, moves it along
+// as it's dragged.
+func (m *Model) handleDrag(msg tea.MouseMsg) tea.Cmd {
+	if !m.dragAndDrop || msg.Button != tea.MouseButtonLeft {
+		return nil
+	}
+
+	index, ok := m.itemAt(msg.X-m.posX, msg.Y-m.posY)
+
+	switch msg.Action { //nolint:exhaustive
+	case tea.MouseActionPress:
+		if !ok {
+			return nil
+		}
+		m.Select(index)
+		m.dragging = m.canMove()
+		m.dragFrom = m.GlobalIndex()
+
+	case tea.MouseActionMotion:
+		if m.dragging && ok && index != m.Index() {
+			m.moveItem(m.Index(), index)
+		}
+
+	case tea.MouseActionRelease:
+		if !m.dragging {
+			return nil
+		}
+		m.dragging = false
+		if from, to := m.dragFrom, m.GlobalIndex(); from != to {
+			return reorderedCmd(from, to)
+		}
+	}
+	return nil
+}
+
+// itemAt returns the index of the visible item rendered at the given
+// position, relative to the top left corner of the list.
+func (m Model) itemAt(x, y int) (int, bool) {
+	if x < 0 || x >= m.width {
+		return 0, false
+	}
+
+	if m.showTitle || (m.showFilter && m.filteringEnabled) {
+		y -= lipgloss.Height(m.titleView())
+	}
+	if m.showStatusBar {
+		y -= lipgloss.Height(m.statusView())
+	}
+	if y < 0 || y >= m.viewHeight {
+		return 0, false
+	}
+
+	items := m.VisibleItems()
+	if len(items) == 0 {
+		return 0, false
+	}
+
+	var start, end, first, line int
+	if m.scrollMode {
+		// Start from the item at the given line of all items.
+		y += m.scrollOffset
+		start = sort.Search(len(items), func(i int) bool {
+			return m.blockBottom(i) > y
+		})
+		end, line = len(items), m.blockTop(start)
+	} else {
+		start, end = m.pageBounds(m.Paginator.Page)
+		first = start
+	}
+
+	spacing, headerH := m.delegate.Spacing(), m.sectionHeaderHeight()
+	for i := start; i < end; i++ {
+		if headerBefore(items, i, first) {
+			line += headerH
+		}
+		h := m.itemHeight(items[i])
+		if y < line {
+			return 0, false
+		}
+		if y < line+h {
+			return i, true
+		}
+		line += h + spacing
+	}
+	return 0, false
+}
+
+// movedIndex returns where the item at index i ends up when the item at from
+// is moved to to.
+func movedIndex(i, from, to int) int {
+	switch {
+	case i == from:
+		return to
+	case from < to && i > from && i <= to:
+		return i - 1
+	case from > to && i >= to && i < from:
+		return i + 1
+	}
+	return i
+}
+
+func reorderedCmd(from, to int) tea.Cmd {
+	return func() tea.Msg {
+		return ItemsReorderedMsg{From: from, To: to}
+	}
+}
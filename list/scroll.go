@@ -0,0 +1,246 @@
+package list
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const defaultMouseWheelDelta = 3
+
+// ScrollMode returns whether the list scrolls continuously instead of
+// paginating.
+func (m Model) ScrollMode() bool {
+	return m.scrollMode
+}
+
+// SetScrollMode switches between pagination and continuous scrolling. When
+// scrolling, the view moves line by line to keep the cursor visible and
+// ScrollMargin items around it, and the paginator is replaced by a scroll
+// indicator.
+func (m *Model) SetScrollMode(v bool) {
+	m.scrollMode = v
+	m.scrollOffset = 0
+	m.updatePagination()
+	m.updateKeybindings()
+}
+
+// ScrollOffset returns the number of lines scrolled past in scroll mode.
+func (m Model) ScrollOffset() int {
+	return m.scrollOffset
+}
+
+// ScrollUp scrolls up the given number of lines in scroll mode, moving the
+// cursor along if it would leave the view.
+func (m *Model) ScrollUp(n int) {
+	m.scrollBy(-n)
+}
+
+// ScrollDown scrolls down the given number of lines in scroll mode, moving
+// the cursor along if it would leave the view.
+func (m *Model) ScrollDown(n int) {
+	m.scrollBy(n)
+}
+
+// handleMouse scrolls with the mouse wheel, or moves the cursor when the
+// list is paginated.
+func (m *Model) handleMouse(msg tea.MouseMsg) {
+	if msg.Action != tea.MouseActionPress {
+		return
+	}
+
+	switch msg.Button { //nolint:exhaustive
+	case tea.MouseButtonWheelUp:
+		if m.scrollMode {
+			m.ScrollUp(m.MouseWheelDelta)
+			return
+		}
+		m.CursorUp()
+	case tea.MouseButtonWheelDown:
+		if m.scrollMode {
+			m.ScrollDown(m.MouseWheelDelta)
+			return
+		}
+		m.CursorDown()
+	}
+}
+
+// layoutLines returns the first line of each visible item, including its
+// section header, followed by the line after the last item and its spacing.
+// It returns nil when every item takes the same number of lines.
+func (m Model) layoutLines(items []Item) []int {
+	if m.uniformLayout(items) {
+		return nil
+	}
+
+	var (
+		tops    = make([]int, len(items)+1)
+		line    int
+		spacing = m.delegate.Spacing()
+		headerH = m.sectionHeaderHeight()
+	)
+	for i, item := range items {
+		tops[i] = line
+		if headerBefore(items, i, 0) {
+			line += headerH
+		}
+		line += m.itemHeight(item) + spacing
+	}
+	tops[len(items)] = line
+	return tops
+}
+
+// blockTop returns the first line of the visible item at the given index,
+// including its section header.
+func (m Model) blockTop(i int) int {
+	if m.lineTops != nil {
+		return m.lineTops[clamp(i, 0, len(m.lineTops)-1)]
+	}
+	return i * (m.delegate.Height() + m.delegate.Spacing())
+}
+
+// blockBottom returns the line after the visible item at the given index,
+// excluding its spacing.
+func (m Model) blockBottom(i int) int {
+	return m.blockTop(i+1) - m.delegate.Spacing()
+}
+
+// contentLines returns the number of lines taken by all visible items.
+func (m Model) contentLines(items []Item) int {
+	return max(0, m.blockBottom(len(items)-1))
+}
+
+// maxScrollOffset returns the largest scroll offset that still fills the
+// view.
+func (m Model) maxScrollOffset(items []Item) int {
+	return max(0, m.contentLines(items)-m.viewHeight)
+}
+
+// cursorBounds returns the lines that must be in view for the cursor to be
+// on the given item, taking ScrollMargin and sticky section headers into
+// account.
+func (m Model) cursorBounds(items []Item, index int) (upper, lower int) {
+	above := clamp(index-m.ScrollMargin, 0, len(items)-1)
+	below := clamp(index+m.ScrollMargin, 0, len(items)-1)
+
+	upper = m.blockTop(above)
+	if !headerBefore(items, above, 0) {
+		if _, ok := groupOf(items[above]); ok {
+			// Leave room for the sticky header.
+			upper -= m.sectionHeaderHeight()
+		}
+	}
+	return max(0, upper), m.blockBottom(below)
+}
+
+// scrollToCursor scrolls as little as possible to bring the cursor into
+// view.
+func (m *Model) scrollToCursor() {
+	if !m.scrollMode {
+		return
+	}
+
+	items := m.VisibleItems()
+	if len(items) == 0 {
+		m.scrollOffset = 0
+		return
+	}
+
+	upper, lower := m.cursorBounds(items, clamp(m.Index(), 0, len(items)-1))
+	if lower > m.scrollOffset+m.viewHeight {
+		m.scrollOffset = lower - m.viewHeight
+	}
+	if upper < m.scrollOffset {
+		m.scrollOffset = upper
+	}
+	m.scrollOffset = clamp(m.scrollOffset, 0, m.maxScrollOffset(items))
+}
+
+// scrollBy scrolls the given number of lines and moves the cursor to stay in
+// view.
+func (m *Model) scrollBy(delta int) {
+	if !m.scrollMode {
+		return
+	}
+
+	items := m.VisibleItems()
+	if len(items) == 0 {
+		return
+	}
+	m.scrollOffset = clamp(m.scrollOffset+delta, 0, m.maxScrollOffset(items))
+
+	index := clamp(m.Index(), 0, len(items)-1)
+	for index < len(items)-1 {
+		if upper, _ := m.cursorBounds(items, index); upper >= m.scrollOffset {
+			break
+		}
+		index++
+	}
+	for index > 0 {
+		if _, lower := m.cursorBounds(items, index); lower <= m.scrollOffset+m.viewHeight {
+			break
+		}
+		index--
+	}
+	m.cursor = index
+	m.scrollToCursor()
+}
+
+// scrollView renders the visible items in view in scroll mode, with the
+// header of the section at the top kept in place.
+func (m Model) scrollView(items []Item) string {
+	n := len(items)
+
+	// Find the first item ending below the top of the view.
+	first := sort.Search(n, func(i int) bool {
+		return m.blockBottom(i) > m.scrollOffset
+	})
+	first = min(first, n-1)
+	last := first
+	for last < n && m.blockTop(last) < m.scrollOffset+m.viewHeight {
+		last++
+	}
+
+	var b strings.Builder
+	m.renderItems(&b, items, first, last, 0)
+
+	lines := strings.Split(b.String(), "\n")
+	if skip := m.scrollOffset - m.blockTop(first); skip > 0 {
+		lines = lines[min(skip, len(lines)):]
+	} else if skip < 0 {
+		lines = append(make([]string, -skip), lines...)
+	}
+	lines = lines[:min(len(lines), m.viewHeight)]
+
+	// Keep the header of the topmost section visible.
+	top := m.scrollOffset <= m.blockTop(first) && headerBefore(items, first, 0)
+	if name, ok := groupOf(items[first]); ok && !top {
+		header := strings.Split(m.sectionHeaderView(name), "\n")
+		copy(lines, header[:min(len(header), len(lines))])
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// scrollIndicatorView renders the position of the cursor in scroll mode,
+// with arrows telling whether there are more items above or below.
+func (m Model) scrollIndicatorView() string {
+	items := m.VisibleItems()
+	maxOffset := m.maxScrollOffset(items)
+	if maxOffset == 0 {
+		return ""
+	}
+
+	up, down := " ", " "
+	if m.scrollOffset > 0 {
+		up = "↑"
+	}
+	if m.scrollOffset < maxOffset {
+		down = "↓"
+	}
+	return m.Styles.ScrollIndicator.Render(
+		fmt.Sprintf("%s %d/%d %s", up, m.Index()+1, len(items), down),
+	)
+}
@@ -0,0 +1,189 @@
+package list
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Grouped is an optional interface for items organized into named sections.
+// Consecutive items in the same group are rendered under a shared section
+// header, which is repeated at the top of each page the section spans.
+// Items that don't implement Grouped, or return an empty group, are rendered
+// without a header.
+type Grouped interface {
+	Item
+
+	// Group is the name of the section the item belongs to.
+	Group() string
+}
+
+// groupOf returns the section name of the given item, if any.
+func groupOf(item Item) (string, bool) {
+	g, ok := item.(Grouped)
+	if !ok {
+		return "", false
+	}
+	name := g.Group()
+	return name, name != ""
+}
+
+// hasSections returns whether any of the given items belongs to a section.
+func hasSections(items []Item) bool {
+	for _, item := range items {
+		if _, ok := groupOf(item); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// headerBefore returns whether a section header is rendered before the item
+// at the given index of the visible items, when first is the index of the
+// first item rendered on the page.
+func headerBefore(items []Item, i, first int) bool {
+	name, ok := groupOf(items[i])
+	if !ok {
+		return false
+	}
+	if i == first {
+		return true
+	}
+	prev, _ := groupOf(items[i-1])
+	return prev != name
+}
+
+// groupMatches orders filter matches by section, in the order the sections
+// first appear in the unfiltered items, so that sections stay contiguous.
+// Matches within a section keep their order.
+func groupMatches(items []Item, matches filteredItems) filteredItems {
+	if !hasSections(items) {
+		return matches
+	}
+
+	rank := make(map[string]int)
+	for _, item := range items {
+		name, _ := groupOf(item)
+		if _, ok := rank[name]; !ok {
+			rank[name] = len(rank)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, _ := groupOf(matches[i].item)
+		b, _ := groupOf(matches[j].item)
+		return rank[a] < rank[b]
+	})
+	return matches
+}
+
+// sectionHeaderView renders the header of the given section.
+func (m Model) sectionHeaderView(name string) string {
+	s := m.Styles.SectionHeader
+	name = ansi.Truncate(name, m.width-s.GetHorizontalFrameSize(), ellipsis)
+	return s.Render(name)
+}
+
+// sectionHeaderHeight returns the number of lines taken by a section header.
+func (m Model) sectionHeaderHeight() int {
+	return lipgloss.Height(m.sectionHeaderView(" "))
+}
+
+// itemHeight returns the number of lines taken by the given item.
+func (m Model) itemHeight(item Item) int {
+	if d, ok := m.delegate.(VariableHeightDelegate); ok {
+		return max(1, d.HeightOf(item))
+	}
+	return m.delegate.Height()
+}
+
+// uniformLayout returns whether every item takes the same number of lines,
+// in which case pages and line positions can be computed arithmetically.
+func (m Model) uniformLayout(items []Item) bool {
+	if _, ok := m.delegate.(VariableHeightDelegate); ok {
+		return false
+	}
+	return !hasSections(items)
+}
+
+// layoutPages splits the visible items into pages fitting the given height
+// and returns the index of the first item of each page. It returns nil when
+// every page holds Paginator.PerPage items.
+func (m Model) layoutPages(items []Item, height int) []int {
+	if m.uniformLayout(items) {
+		return nil
+	}
+
+	var (
+		starts  []int
+		used    int
+		spacing = m.delegate.Spacing()
+		headerH = m.sectionHeaderHeight()
+	)
+	for i, item := range items {
+		cost := m.itemHeight(item) + spacing
+		if len(starts) > 0 {
+			first := starts[len(starts)-1]
+			if headerBefore(items, i, first) {
+				cost += headerH
+			}
+			if used+cost <= height {
+				used += cost
+				continue
+			}
+		}
+
+		// Start a new page with this item, repeating its section header.
+		starts = append(starts, i)
+		used = m.itemHeight(item) + spacing
+		if headerBefore(items, i, i) {
+			used += headerH
+		}
+	}
+	return starts
+}
+
+// pageBounds returns the bounds of the visible items on the given page.
+func (m Model) pageBounds(page int) (start, end int) {
+	n := len(m.VisibleItems())
+	if m.pageStarts == nil {
+		start = page * m.Paginator.PerPage
+		return start, min(start+m.Paginator.PerPage, n)
+	}
+	if len(m.pageStarts) == 0 {
+		return 0, 0
+	}
+
+	page = clamp(page, 0, len(m.pageStarts)-1)
+	start = min(m.pageStarts[page], n)
+	end = n
+	if page+1 < len(m.pageStarts) {
+		end = min(m.pageStarts[page+1], n)
+	}
+	return start, end
+}
+
+// pageOf returns the page the visible item at the given index is on.
+func (m Model) pageOf(index int) int {
+	if m.pageStarts == nil {
+		return index / m.Paginator.PerPage
+	}
+	return max(0, sort.SearchInts(m.pageStarts, index+1)-1)
+}
+
+// renderItems renders the visible items between start and end, including
+// their section headers, where first is the first item on the page.
+func (m Model) renderItems(b *strings.Builder, items []Item, start, end, first int) {
+	for i := start; i < end; i++ {
+		if i > start {
+			b.WriteString(strings.Repeat("\n", m.delegate.Spacing()+1))
+		}
+		if headerBefore(items, i, first) {
+			name, _ := groupOf(items[i])
+			b.WriteString(m.sectionHeaderView(name) + "\n")
+		}
+		m.delegate.Render(b, m, i, items[i])
+	}
+}
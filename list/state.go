@@ -0,0 +1,94 @@
+package list
+
+// Identifiable is an optional interface for items with a stable identity,
+// used to find the selected item again when restoring a State after the
+// items were reloaded.
+type Identifiable interface {
+	Item
+
+	// ID uniquely identifies the item among the list's items.
+	ID() string
+}
+
+// State is a serializable snapshot of the view state of a list, taken with
+// Model.State and restored with Model.RestoreState.
+type State struct {
+	// The filter value and whether it's being edited or applied.
+	FilterValue string      `json:"filter_value,omitempty"`
+	FilterState FilterState `json:"filter_state,omitempty"`
+
+	// The ID of the selected item, if it implements Identifiable, and its
+	// index among the visible items.
+	SelectedID string `json:"selected_id,omitempty"`
+	Index      int    `json:"index"`
+
+	// The current page, and the lines scrolled past in scroll mode.
+	Page         int `json:"page"`
+	ScrollOffset int `json:"scroll_offset,omitempty"`
+
+	// Whether the full help is shown.
+	ShowFullHelp bool `json:"show_full_help,omitempty"`
+}
+
+// State returns a snapshot of the view state of the list, e.g. to restore
+// it when coming back to a screen.
+func (m Model) State() State {
+	s := State{
+		FilterState:  m.filterState,
+		Index:        m.Index(),
+		Page:         m.Paginator.Page,
+		ScrollOffset: m.scrollOffset,
+		ShowFullHelp: m.Help.ShowAll,
+	}
+	if m.filterState != Unfiltered {
+		s.FilterValue = m.FilterInput.Value()
+	}
+	if i, ok := m.SelectedItem().(Identifiable); ok {
+		s.SelectedID = i.ID()
+	}
+	return s
+}
+
+// RestoreState restores a snapshot taken with State. The filter is applied
+// to the current items, and the selected item is found by its ID if it
+// implements Identifiable, so the state survives reloading the items with
+// SetItems. Otherwise the selection is restored by index, or failing that by
+// page.
+func (m *Model) RestoreState(s State) {
+	m.Help.ShowAll = s.ShowFullHelp
+
+	m.resetFiltering()
+	applied := s.FilterState == FilterApplied && s.FilterValue != ""
+	if m.filteringEnabled && (applied || s.FilterState == Filtering) {
+		m.SetFilterText(s.FilterValue)
+		if s.FilterState == Filtering {
+			m.SetFilterState(Filtering)
+		}
+	}
+	m.updatePagination()
+
+	items := m.VisibleItems()
+	index := -1
+	if s.SelectedID != "" {
+		for i, item := range items {
+			if item, ok := item.(Identifiable); ok && item.ID() == s.SelectedID {
+				index = i
+				break
+			}
+		}
+	}
+	if index < 0 && s.Index >= 0 && s.Index < len(items) {
+		index = s.Index
+	}
+
+	switch {
+	case index >= 0:
+		m.scrollOffset = s.ScrollOffset
+		m.Select(index)
+	default:
+		m.Paginator.Page = clamp(s.Page, 0, max(0, m.Paginator.TotalPages-1))
+		m.cursor = 0
+		m.scrollToCursor()
+	}
+	m.updateKeybindings()
+}
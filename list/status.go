@@ -0,0 +1,113 @@
+package list
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SetItemBadge sets a short status text, such as "deployed" or "42%", shown
+// next to the item at the given index of the unfiltered list. An empty badge
+// removes it. Badges are cleared by SetItems.
+func (m *Model) SetItemBadge(index int, badge string) {
+	if index < 0 || index >= len(m.items) {
+		return
+	}
+	if badge == "" {
+		delete(m.badges, index)
+		return
+	}
+	if m.badges == nil {
+		m.badges = make(map[int]string)
+	}
+	m.badges[index] = badge
+}
+
+// ItemBadge returns the badge of the item at the given index of the
+// unfiltered list, if any.
+func (m Model) ItemBadge(index int) string {
+	return m.badges[index]
+}
+
+// StartItemSpinner shows a spinner next to the item at the given index of
+// the unfiltered list, e.g. while background work for the item is running.
+// All item spinners are driven by a single tick, which is started by the
+// returned command if it isn't running yet. Spinners are cleared by
+// SetItems.
+func (m *Model) StartItemSpinner(index int) tea.Cmd {
+	if index < 0 || index >= len(m.items) {
+		return nil
+	}
+
+	ticking := len(m.busy) > 0
+	if m.busy == nil {
+		m.busy = make(map[int]struct{})
+	}
+	m.busy[index] = struct{}{}
+
+	if ticking {
+		return nil
+	}
+	return m.itemSpinner.Tick
+}
+
+// StopItemSpinner hides the spinner of the item at the given index of the
+// unfiltered list. The shared tick stops once no item spinner is left.
+func (m *Model) StopItemSpinner(index int) {
+	delete(m.busy, index)
+}
+
+// ItemBusy returns whether the item at the given index of the unfiltered
+// list shows a spinner.
+func (m Model) ItemBusy(index int) bool {
+	_, ok := m.busy[index]
+	return ok
+}
+
+// ItemSpinnerView renders the current frame of the item spinners, for use
+// by delegates.
+func (m Model) ItemSpinnerView() string {
+	return m.itemSpinner.View()
+}
+
+// SetPlaceholders shows the given number of skeleton items while the list
+// has no items, e.g. while they're still loading. Placeholders are removed
+// by SetItems.
+func (m *Model) SetPlaceholders(n int) {
+	m.placeholders = max(0, n)
+	m.updatePagination()
+}
+
+// Placeholders returns the number of skeleton items shown while the list has
+// no items.
+func (m Model) Placeholders() int {
+	return m.placeholders
+}
+
+// placeholderView renders the skeleton items filling the page.
+func (m Model) placeholderView() string {
+	// Vary the width of the placeholders to look more like text.
+	widths := []int{60, 45, 75, 50} //nolint:mnd
+
+	var (
+		b       strings.Builder
+		height  = m.delegate.Height()
+		spacing = m.delegate.Spacing()
+		s       = m.Styles.Placeholder
+		avail   = m.width - s.GetHorizontalFrameSize()
+		n       = min(m.placeholders, m.Paginator.PerPage)
+	)
+	for i := range n {
+		if i > 0 {
+			b.WriteString(strings.Repeat("\n", spacing+1))
+		}
+		for line := range height {
+			if line > 0 {
+				b.WriteString("\n")
+			}
+			w := max(1, avail*widths[(i+line)%len(widths)]/100) //nolint:mnd
+			b.WriteString(s.Render(strings.Repeat("░", w)))
+		}
+	}
+	return b.String()
+}
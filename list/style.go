@@ -26,9 +26,16 @@ type Styles struct {
 	StatusEmpty           lipgloss.Style
 	StatusBarActiveFilter lipgloss.Style
 	StatusBarFilterCount  lipgloss.Style
+	StatusBarMarkedCount  lipgloss.Style
 
 	NoItems lipgloss.Style
 
+	// Skeleton items shown while items are loading.
+	Placeholder lipgloss.Style
+
+	// Headers of sections of Grouped items.
+	SectionHeader lipgloss.Style
+
 	PaginationStyle lipgloss.Style
 	HelpStyle       lipgloss.Style
 
@@ -36,6 +43,7 @@ type Styles struct {
 	ActivePaginationDot   lipgloss.Style
 	InactivePaginationDot lipgloss.Style
 	ArabicPagination      lipgloss.Style
+	ScrollIndicator       lipgloss.Style
 	DividerDot            lipgloss.Style
 }
 
@@ -74,11 +82,25 @@ func DefaultStyles() (s Styles) {
 
 	s.StatusBarFilterCount = lipgloss.NewStyle().Foreground(verySubduedColor)
 
+	s.StatusBarMarkedCount = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#04B575", Dark: "#04B575"})
+
 	s.NoItems = lipgloss.NewStyle().
 		Foreground(lipgloss.AdaptiveColor{Light: "#909090", Dark: "#626262"})
 
+	s.Placeholder = lipgloss.NewStyle().
+		Foreground(verySubduedColor).
+		Padding(0, 0, 0, 2) //nolint:mnd
+
+	s.SectionHeader = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#847A85", Dark: "#979797"}).
+		Bold(true).
+		Padding(0, 0, 0, 2) //nolint:mnd
+
 	s.ArabicPagination = lipgloss.NewStyle().Foreground(subduedColor)
 
+	s.ScrollIndicator = lipgloss.NewStyle().Foreground(subduedColor)
+
 	s.PaginationStyle = lipgloss.NewStyle().PaddingLeft(2) //nolint:mnd
 
 	s.HelpStyle = lipgloss.NewStyle().Padding(1, 0, 0, 2) //nolint:mnd
@@ -0,0 +1,279 @@
+package table
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// StyleFunc returns a style for the cell at the given row and column index.
+// It's applied on top of Styles.Cell and shouldn't change the size of the
+// cell, i.e. it shouldn't set padding, margins or borders.
+type StyleFunc func(row, col int) lipgloss.Style
+
+// ValidateFunc checks the value of an edited cell before it's committed. A
+// non-nil error keeps the cell in edit mode.
+type ValidateFunc func(row, col int, value string) error
+
+// EditCommittedMsg is sent when the edit of a cell has been committed.
+type EditCommittedMsg struct {
+	Row      int
+	Col      int
+	Value    string
+	Previous string
+}
+
+// WithStyleFunc sets a function used to style individual cells.
+func WithStyleFunc(fn StyleFunc) Option {
+	return func(m *Model) {
+		m.styleFunc = fn
+	}
+}
+
+// WithCellNavigation enables or disables moving the cursor between the cells
+// of the selected row.
+func WithCellNavigation(v bool) Option {
+	return func(m *Model) {
+		m.cellNavigation = v
+	}
+}
+
+// WithEditing enables or disables inline editing of the selected cell. It
+// only takes effect when cell navigation is enabled.
+func WithEditing(v bool) Option {
+	return func(m *Model) {
+		m.editable = v
+	}
+}
+
+// WithValidateFunc sets the function used to validate edited cells.
+func WithValidateFunc(fn ValidateFunc) Option {
+	return func(m *Model) {
+		m.validate = fn
+	}
+}
+
+// SetStyleFunc sets a function used to style individual cells.
+func (m *Model) SetStyleFunc(fn StyleFunc) {
+	m.styleFunc = fn
+	m.UpdateViewport()
+}
+
+// SetCellNavigation enables or disables moving the cursor between the cells
+// of the selected row.
+func (m *Model) SetCellNavigation(v bool) {
+	m.cellNavigation = v
+	if !v {
+		m.CancelEdit()
+	}
+	m.UpdateViewport()
+}
+
+// CellNavigation returns whether cell navigation is enabled.
+func (m Model) CellNavigation() bool {
+	return m.cellNavigation
+}
+
+// SetEditing enables or disables inline editing of the selected cell.
+func (m *Model) SetEditing(v bool) {
+	m.editable = v
+	if !v {
+		m.CancelEdit()
+	}
+}
+
+// SetValidateFunc sets the function used to validate edited cells.
+func (m *Model) SetValidateFunc(fn ValidateFunc) {
+	m.validate = fn
+}
+
+// ColumnCursor returns the index of the selected column.
+func (m Model) ColumnCursor() int {
+	return m.colCursor
+}
+
+// SetColumnCursor sets the selected column, scrolling horizontally if needed
+// to bring it into view.
+func (m *Model) SetColumnCursor(n int) {
+	m.colCursor = clamp(n, 0, len(m.cols)-1)
+	m.scrollToColumn(m.colCursor)
+	m.UpdateViewport()
+}
+
+// SelectedCell returns the value of the selected cell.
+func (m Model) SelectedCell() string {
+	row := m.SelectedRow()
+	if m.colCursor < 0 || m.colCursor >= len(row) {
+		return ""
+	}
+	return row[m.colCursor]
+}
+
+// MoveLeft moves the column cursor left by n visible columns.
+func (m *Model) MoveLeft(n int) {
+	for i := m.colCursor - 1; i >= 0 && n > 0; i-- {
+		if m.colWidth(i) > 0 {
+			m.colCursor = i
+			n--
+		}
+	}
+	m.scrollToColumn(m.colCursor)
+	m.UpdateViewport()
+}
+
+// MoveRight moves the column cursor right by n visible columns.
+func (m *Model) MoveRight(n int) {
+	for i := m.colCursor + 1; i < len(m.cols) && n > 0; i++ {
+		if m.colWidth(i) > 0 {
+			m.colCursor = i
+			n--
+		}
+	}
+	m.scrollToColumn(m.colCursor)
+	m.UpdateViewport()
+}
+
+// Editing returns whether a cell is currently being edited.
+func (m Model) Editing() bool {
+	return m.editing
+}
+
+// EditError returns the error returned by the validation function for the
+// last attempt to commit an edit, if any.
+func (m Model) EditError() error {
+	return m.editErr
+}
+
+// StartEdit starts editing the selected cell. It does nothing unless editing
+// and cell navigation are enabled.
+func (m *Model) StartEdit() tea.Cmd {
+	if !m.editable || !m.cellNavigation || m.SelectedRow() == nil || len(m.cols) == 0 {
+		return nil
+	}
+
+	m.input = textinput.New()
+	m.input.Prompt = ""
+	m.input.Width = max(m.colWidth(m.colCursor)-1, 1)
+	m.input.SetValue(m.SelectedCell())
+	m.editing = true
+	m.editErr = nil
+	cmd := m.input.Focus()
+	m.UpdateViewport()
+	return cmd
+}
+
+// CancelEdit stops editing the selected cell, discarding any changes.
+func (m *Model) CancelEdit() {
+	if !m.editing {
+		return
+	}
+	m.editing = false
+	m.editErr = nil
+	m.input.Blur()
+	m.UpdateViewport()
+}
+
+// CommitEdit validates the edited value and, if it's valid, writes it to the
+// selected cell and returns a command sending an EditCommittedMsg. If
+// validation fails the cell stays in edit mode; see EditError.
+//
+// When rows are provided by a data source only the cached row is updated;
+// the application is expected to persist the change upon receiving the
+// message.
+func (m *Model) CommitEdit() tea.Cmd {
+	if !m.editing {
+		return nil
+	}
+
+	row, col, value := m.cursorRow(), m.colCursor, m.input.Value()
+	if m.validate != nil {
+		if err := m.validate(row, col, value); err != nil {
+			m.editErr = err
+			return nil
+		}
+	}
+
+	r := append(Row(nil), m.row(row)...)
+	for len(r) <= col {
+		r = append(r, "")
+	}
+	previous := r[col]
+	r[col] = value
+	m.setRow(row, r)
+
+	m.editing = false
+	m.editErr = nil
+	m.input.Blur()
+	m.rebuildView()
+	m.UpdateViewport()
+
+	return func() tea.Msg {
+		return EditCommittedMsg{Row: row, Col: col, Value: value, Previous: previous}
+	}
+}
+
+// updateEditing handles messages while a cell is being edited.
+func (m Model) updateEditing(msg tea.Msg) (Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.keys().CommitEdit):
+			return m, m.CommitEdit()
+		case key.Matches(msg, m.keys().CancelEdit):
+			m.CancelEdit()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.UpdateViewport()
+	return m, cmd
+}
+
+// scrollToColumn adjusts the horizontal scroll offset so that column i is
+// visible. Frozen columns are always visible.
+func (m *Model) scrollToColumn(i int) {
+	if i < m.frozen || i >= len(m.cols) {
+		return
+	}
+
+	// The offset counts scrollable columns that have a width.
+	offset := 0
+	for j := m.frozen; j < i; j++ {
+		if m.colWidth(j) > 0 {
+			offset++
+		}
+	}
+	if offset < m.colOffset {
+		m.colOffset = offset
+		return
+	}
+	for !m.isColumnVisible(i) && m.colOffset < offset {
+		m.colOffset++
+	}
+}
+
+// isColumnVisible reports whether column i is currently rendered.
+func (m Model) isColumnVisible(i int) bool {
+	for _, j := range m.visibleColumns() {
+		if j == i {
+			return true
+		}
+	}
+	return false
+}
+
+// cellStyle returns the style used to render the cell at the given row and
+// column, not including the padding from Styles.Cell.
+func (m Model) cellStyle(row, col int) lipgloss.Style {
+	style := lipgloss.NewStyle()
+	if m.styleFunc != nil {
+		style = m.styleFunc(row, col)
+	}
+	if m.cellNavigation && row == m.cursorRow() && col == m.colCursor {
+		style = m.styles.SelectedCell.Inherit(style)
+	}
+	return style
+}
@@ -0,0 +1,212 @@
+package table
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// ColumnWidths returns the widths of the columns as resolved against the
+// table width. Hidden columns have a width of zero.
+func (m Model) ColumnWidths() []int {
+	widths := make([]int, len(m.cols))
+	for i := range m.cols {
+		widths[i] = m.colWidth(i)
+	}
+	return widths
+}
+
+// FrozenColumns returns the number of leading columns that stay in place when
+// scrolling horizontally.
+func (m Model) FrozenColumns() int {
+	return m.frozen
+}
+
+// SetFrozenColumns freezes the first n columns so that they stay visible when
+// scrolling horizontally.
+func (m *Model) SetFrozenColumns(n int) {
+	m.frozen = max(n, 0)
+	m.colOffset = 0
+	m.UpdateViewport()
+}
+
+// ColumnOffset returns the number of scrollable columns scrolled past on the
+// left.
+func (m Model) ColumnOffset() int {
+	return m.colOffset
+}
+
+// ScrollLeft scrolls the table left by n columns. Frozen columns are not
+// affected.
+func (m *Model) ScrollLeft(n int) {
+	m.colOffset = max(m.colOffset-n, 0)
+	m.UpdateViewport()
+}
+
+// ScrollRight scrolls the table right by n columns. It stops once the last
+// column is visible.
+func (m *Model) ScrollRight(n int) {
+	for ; n > 0 && m.canScrollRight(); n-- {
+		m.colOffset++
+	}
+	m.UpdateViewport()
+}
+
+// canScrollRight reports whether there are columns hidden beyond the right
+// edge of the table.
+func (m Model) canScrollRight() bool {
+	visible := m.visibleColumns()
+	if len(visible) == 0 {
+		return false
+	}
+	for i := visible[len(visible)-1] + 1; i < len(m.cols); i++ {
+		if m.colWidth(i) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// colWidth returns the resolved width of column i, falling back to the
+// column's configured width if widths haven't been resolved yet.
+func (m Model) colWidth(i int) int {
+	if len(m.widths) == len(m.cols) {
+		return m.widths[i]
+	}
+	return m.cols[i].Width
+}
+
+// visibleColumns returns the indices of the columns to render, in order. The
+// frozen columns always come first, followed by as many scrollable columns
+// from the current offset as fit in the table width. When no width is set
+// every column is visible.
+func (m Model) visibleColumns() []int {
+	frame := m.styles.Cell.GetHorizontalFrameSize()
+	frozen := min(m.frozen, len(m.cols))
+
+	visible := make([]int, 0, len(m.cols))
+	used := 0
+	for i := range frozen {
+		if w := m.colWidth(i); w > 0 {
+			visible = append(visible, i)
+			used += w + frame
+		}
+	}
+
+	skipped := 0
+	scrollable := 0
+	for i := frozen; i < len(m.cols); i++ {
+		w := m.colWidth(i)
+		if w <= 0 {
+			continue
+		}
+		if skipped < m.colOffset {
+			skipped++
+			continue
+		}
+		// Always show at least one scrollable column, even if it's too wide.
+		if m.viewport.Width > 0 && scrollable > 0 && used+w+frame > m.viewport.Width {
+			break
+		}
+		visible = append(visible, i)
+		used += w + frame
+		scrollable++
+	}
+	return visible
+}
+
+// resolveWidths computes the width of every column from its width mode and
+// the table width.
+func (m *Model) resolveWidths() {
+	if len(m.cols) == 0 {
+		m.widths = nil
+		return
+	}
+
+	if len(m.fitWidths) != len(m.cols) {
+		m.fitWidths = make([]int, len(m.cols))
+		for i, col := range m.cols {
+			if col.Fit {
+				m.fitWidths[i] = m.contentWidth(i)
+			}
+		}
+	}
+
+	frame := m.styles.Cell.GetHorizontalFrameSize()
+	widths := make([]int, len(m.cols))
+
+	var used, totalFlex int
+	for i, col := range m.cols {
+		switch {
+		case col.Flex > 0:
+			totalFlex += col.Flex
+			used += frame
+			continue
+		case col.Fit:
+			widths[i] = col.bound(m.fitWidths[i])
+		default:
+			widths[i] = col.bound(col.Width)
+		}
+		if widths[i] > 0 {
+			used += widths[i] + frame
+		}
+	}
+
+	remaining := m.viewport.Width - used
+	var flex, shared int
+	for i, col := range m.cols {
+		if col.Flex <= 0 {
+			continue
+		}
+		if m.viewport.Width <= 0 || remaining <= 0 {
+			// There's no room to share, so fall back to the smallest sensible
+			// width and let horizontal scrolling take care of the rest.
+			widths[i] = col.bound(max(col.Width, runewidth.StringWidth(col.Title)))
+			continue
+		}
+		// Share cumulatively so that rounding errors don't leave a gap at the
+		// end of the table.
+		flex += col.Flex
+		share := remaining*flex/totalFlex - shared
+		shared += share
+		widths[i] = col.bound(share)
+	}
+
+	m.widths = widths
+}
+
+// contentWidth returns the width of the widest value in column i, including
+// its title. Only rows held in memory are measured.
+func (m Model) contentWidth(i int) int {
+	w := runewidth.StringWidth(m.cols[i].Title)
+	m.loadedRows(func(row Row) {
+		if i < len(row) {
+			w = max(w, runewidth.StringWidth(row[i]))
+		}
+	})
+	if i == 0 && m.tree != nil {
+		// Make room for the indentation of the deepest node in tree mode.
+		for j := range m.rows {
+			w = max(w, runewidth.StringWidth(m.treePrefix(j)+cellValue(m.rows[j], 0)))
+		}
+	}
+	return w
+}
+
+// bound clamps w to the column's minimum and maximum widths. A zero bound is
+// ignored.
+func (c Column) bound(w int) int {
+	if c.MaxWidth > 0 {
+		w = min(w, c.MaxWidth)
+	}
+	if c.MinWidth > 0 {
+		w = max(w, c.MinWidth)
+	}
+	return w
+}
+
+// renderCell truncates and aligns value to the width of column i.
+func (m Model) renderCell(i int, value string) string {
+	w := m.colWidth(i)
+	style := lipgloss.NewStyle().Width(w).MaxWidth(w).Inline(true).Align(m.cols[i].Align)
+	return style.Render(runewidth.Truncate(value, w, "…"))
+}
@@ -0,0 +1,330 @@
+package table
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Format is a text format rows can be imported from and exported to.
+type Format int
+
+// Available formats. CSV follows RFC 4180 and TSV uses the same quoting rules
+// with a tab as separator; the first record holds the column titles. JSON is
+// an array of objects keyed by column title.
+const (
+	CSV Format = iota
+	TSV
+	JSON
+)
+
+// String returns the name of the format.
+func (f Format) String() string {
+	switch f {
+	case CSV:
+		return "csv"
+	case TSV:
+		return "tsv"
+	case JSON:
+		return "json"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// ErrUnknownFormat is returned when importing or exporting an unknown format.
+var ErrUnknownFormat = errors.New("table: unknown format")
+
+// Decode reads columns and rows from r. Columns are inferred from the header
+// record or the object keys, in order of appearance, and are sized to fit
+// their widest value.
+func Decode(r io.Reader, f Format) ([]Column, []Row, error) {
+	var (
+		titles []string
+		rows   []Row
+		err    error
+	)
+	switch f {
+	case CSV:
+		titles, rows, err = decodeSeparated(r, ',')
+	case TSV:
+		titles, rows, err = decodeSeparated(r, '\t')
+	case JSON:
+		titles, rows, err = decodeJSON(r)
+	default:
+		return nil, nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cols := make([]Column, len(titles))
+	for i, title := range titles {
+		cols[i] = Column{Title: title, Width: runewidth.StringWidth(title)}
+	}
+	for _, row := range rows {
+		for i, value := range row {
+			if i < len(cols) {
+				cols[i].Width = max(cols[i].Width, runewidth.StringWidth(value))
+			}
+		}
+	}
+	return cols, rows, nil
+}
+
+// Encode writes columns and rows to w.
+func Encode(w io.Writer, f Format, cols []Column, rows []Row) error {
+	return encode(w, f, cols, func(fn func(Row) error) error {
+		for _, row := range rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Import replaces the table's columns and rows with the ones read from r.
+func (m *Model) Import(r io.Reader, f Format) error {
+	cols, rows, err := Decode(r, f)
+	if err != nil {
+		return err
+	}
+	m.SetColumns(cols)
+	m.SetRows(rows)
+	return nil
+}
+
+// Export writes the table's columns and rows to w, in the order they are
+// displayed. Rows provided by a data source are fetched from it.
+func (m Model) Export(w io.Writer, f Format) error {
+	return encode(w, f, m.cols, m.eachRow)
+}
+
+// eachRow calls fn for every row in display order, stopping at the first
+// error.
+func (m Model) eachRow(fn func(Row) error) error {
+	if m.source == nil {
+		for _, row := range m.rows {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	n := m.rowCount()
+	for start := 0; start < n; start += m.pageLen() {
+		for _, row := range m.source.Rows(start, min(start+m.pageLen(), n)) {
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func encode(w io.Writer, f Format, cols []Column, each func(func(Row) error) error) error {
+	titles := make([]string, len(cols))
+	for i, col := range cols {
+		titles[i] = col.Title
+	}
+
+	switch f {
+	case CSV:
+		return encodeSeparated(w, ',', titles, each)
+	case TSV:
+		return encodeSeparated(w, '\t', titles, each)
+	case JSON:
+		return encodeJSON(w, titles, each)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+func decodeSeparated(r io.Reader, comma rune) ([]string, []Row, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("table: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+
+	rows := make([]Row, 0, len(records)-1)
+	for _, record := range records[1:] {
+		rows = append(rows, Row(record))
+	}
+	return records[0], rows, nil
+}
+
+func encodeSeparated(w io.Writer, comma rune, titles []string, each func(func(Row) error) error) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+
+	if err := cw.Write(titles); err != nil {
+		return err
+	}
+	err := each(func(row Row) error {
+		record := make([]string, len(titles))
+		copy(record, row)
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func decodeJSON(r io.Reader) ([]string, []Row, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '['); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		titles []string
+		rows   []Row
+	)
+	index := make(map[string]int)
+	for dec.More() {
+		if err := expectDelim(dec, '{'); err != nil {
+			return nil, nil, err
+		}
+		row := make(Row, len(titles))
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, nil, fmt.Errorf("table: %w", err)
+			}
+			key, _ := tok.(string)
+
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return nil, nil, fmt.Errorf("table: %w", err)
+			}
+
+			i, ok := index[key]
+			if !ok {
+				i = len(titles)
+				index[key] = i
+				titles = append(titles, key)
+			}
+			for len(row) <= i {
+				row = append(row, "")
+			}
+			row[i] = jsonValue(raw)
+		}
+		if err := expectDelim(dec, '}'); err != nil {
+			return nil, nil, err
+		}
+		rows = append(rows, row)
+	}
+	if err := expectDelim(dec, ']'); err != nil {
+		return nil, nil, err
+	}
+
+	// Objects decoded before a key first appeared lack its column.
+	for i, row := range rows {
+		for len(row) < len(titles) {
+			row = append(row, "")
+		}
+		rows[i] = row
+	}
+	return titles, rows, nil
+}
+
+func encodeJSON(w io.Writer, titles []string, each func(func(Row) error) error) error {
+	keys := make([][]byte, len(titles))
+	for i, title := range titles {
+		b, err := json.Marshal(title)
+		if err != nil {
+			return err
+		}
+		keys[i] = b
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	first := true
+	err := each(func(row Row) error {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		buf.WriteString("\n  {")
+		for i, key := range keys {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			var value string
+			if i < len(row) {
+				value = row[i]
+			}
+			b, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteString(": ")
+			buf.Write(b)
+		}
+		buf.WriteByte('}')
+
+		// Flush regularly so large exports don't have to fit in memory.
+		if buf.Len() > 1<<16 {
+			if _, err := buf.WriteTo(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !first {
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("]\n")
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// jsonValue converts a JSON value to cell text. Strings are unquoted, null
+// becomes empty and anything else is kept as compact JSON.
+func jsonValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("table: %w", err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("table: expected %q, got %v", want, tok)
+	}
+	return nil
+}
@@ -0,0 +1,421 @@
+package table
+
+import (
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Aggregator computes the summary of a column's values shown in group
+// footers.
+type Aggregator func(values []string) string
+
+// AggregateCount counts the values.
+func AggregateCount(values []string) string {
+	return strconv.Itoa(len(values))
+}
+
+// AggregateSum adds up the numeric values. Values that aren't numbers are
+// ignored.
+func AggregateSum(values []string) string {
+	var sum float64
+	for _, v := range values {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			sum += f
+		}
+	}
+	return strconv.FormatFloat(sum, 'f', -1, 64)
+}
+
+// AggregateMin returns the smallest value, comparing numbers numerically.
+// Empty values are ignored.
+func AggregateMin(values []string) string {
+	return extreme(values, lessValue)
+}
+
+// AggregateMax returns the largest value, comparing numbers numerically.
+// Empty values are ignored.
+func AggregateMax(values []string) string {
+	return extreme(values, func(x, y string) bool { return lessValue(y, x) })
+}
+
+func extreme(values []string, better func(x, y string) bool) string {
+	var (
+		result string
+		found  bool
+	)
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		if !found || better(v, result) {
+			result, found = v, true
+		}
+	}
+	return result
+}
+
+// Grouping configures how rows are grouped.
+type Grouping struct {
+	// Column is the index of the column whose values the rows are grouped
+	// by. Groups appear in the order their key first appears in the rows.
+	Column int
+
+	// Aggregators maps column indices to the aggregator used for that
+	// column in footer rows.
+	Aggregators map[int]Aggregator
+
+	// Footers adds a footer row to every group and GrandTotal adds a footer
+	// row aggregating all rows at the end of the table.
+	Footers    bool
+	GrandTotal bool
+
+	// SkipHeaders makes the cursor skip group headers. Footers are always
+	// skipped.
+	SkipHeaders bool
+}
+
+// rowKind is the kind of a row as displayed in the table.
+type rowKind int
+
+const (
+	dataRow rowKind = iota
+	groupHeaderRow
+	groupFooterRow
+	grandTotalRow
+)
+
+// viewRow is a displayed row. For data rows index refers to the rows of the
+// table, otherwise to its groups.
+type viewRow struct {
+	kind  rowKind
+	index int
+}
+
+// group is a set of rows sharing the same value in the grouping column.
+// totals holds the aggregated values of its rows by column.
+type group struct {
+	key    string
+	rows   []int
+	totals map[int]string
+}
+
+// WithGrouping groups the rows of the table.
+func WithGrouping(g Grouping) Option {
+	return func(m *Model) {
+		m.grouping = &g
+	}
+}
+
+// SetGrouping groups the rows of the table. Groups that were collapsed stay
+// collapsed.
+func (m *Model) SetGrouping(g Grouping) {
+	m.grouping = &g
+	m.rebuildView()
+	m.UpdateViewport()
+}
+
+// ClearGrouping stops grouping the rows.
+func (m *Model) ClearGrouping() {
+	anchor := m.cursorRow()
+	m.grouping = nil
+	m.collapsed = nil
+	m.rebuildViewAt(anchor)
+	m.cursor = clamp(m.cursor, 0, m.rowCount()-1)
+	m.UpdateViewport()
+}
+
+// Grouping returns the current grouping, if any.
+func (m Model) Grouping() (Grouping, bool) {
+	if m.grouping == nil {
+		return Grouping{}, false
+	}
+	return *m.grouping, true
+}
+
+// GroupKeys returns the keys of the groups in display order.
+func (m Model) GroupKeys() []string {
+	keys := make([]string, len(m.groups))
+	for i, g := range m.groups {
+		keys[i] = g.key
+	}
+	return keys
+}
+
+// SelectedGroup returns the key of the group the cursor is in.
+func (m Model) SelectedGroup() (key string, ok bool) {
+	if m.view == nil || m.grouping == nil || m.cursor < 0 || m.cursor >= len(m.view) {
+		return "", false
+	}
+	vr := m.view[m.cursor]
+	switch vr.kind { //nolint:exhaustive
+	case groupHeaderRow, groupFooterRow:
+		if vr.index < len(m.groups) {
+			return m.groups[vr.index].key, true
+		}
+	case dataRow:
+		if vr.index < len(m.rows) {
+			return cellValue(m.rows[vr.index], m.grouping.Column), true
+		}
+	}
+	return "", false
+}
+
+// IsGroupCollapsed returns whether the group with the given key is
+// collapsed.
+func (m Model) IsGroupCollapsed(key string) bool {
+	return m.collapsed[key]
+}
+
+// ToggleGroup collapses the group with the given key if it's expanded and
+// expands it otherwise.
+func (m *Model) ToggleGroup(key string) {
+	if m.IsGroupCollapsed(key) {
+		m.ExpandGroup(key)
+	} else {
+		m.CollapseGroup(key)
+	}
+}
+
+// CollapseGroup hides the rows of the group with the given key.
+func (m *Model) CollapseGroup(key string) {
+	if m.collapsed == nil {
+		m.collapsed = make(map[string]bool)
+	}
+	m.collapsed[key] = true
+	m.rebuildView()
+	m.UpdateViewport()
+}
+
+// ExpandGroup shows the rows of the group with the given key.
+func (m *Model) ExpandGroup(key string) {
+	delete(m.collapsed, key)
+	m.rebuildView()
+	m.UpdateViewport()
+}
+
+// CollapseAllGroups hides the rows of every group.
+func (m *Model) CollapseAllGroups() {
+	m.collapsed = make(map[string]bool, len(m.groups))
+	for _, g := range m.groups {
+		m.collapsed[g.key] = true
+	}
+	m.rebuildView()
+	m.UpdateViewport()
+}
+
+// ExpandAllGroups shows the rows of every group.
+func (m *Model) ExpandAllGroups() {
+	m.collapsed = nil
+	m.rebuildView()
+	m.UpdateViewport()
+}
+
+// onGroupHeader reports whether the cursor is on a group header.
+func (m Model) onGroupHeader() bool {
+	return m.view != nil && m.cursor >= 0 && m.cursor < len(m.view) &&
+		m.view[m.cursor].kind == groupHeaderRow
+}
+
+// viewLen returns the number of displayed rows.
+func (m Model) viewLen() int {
+	if m.view != nil {
+		return len(m.view)
+	}
+	return m.rowCount()
+}
+
+// dataIndex returns the index of the data row displayed at v, or -1 if it
+// isn't a data row.
+func (m Model) dataIndex(v int) int {
+	if m.view == nil {
+		return v
+	}
+	if v < 0 || v >= len(m.view) || m.view[v].kind != dataRow {
+		return -1
+	}
+	return m.view[v].index
+}
+
+// cursorRow returns the index of the data row under the cursor, or -1 if the
+// cursor isn't on a data row.
+func (m Model) cursorRow() int {
+	return m.dataIndex(m.cursor)
+}
+
+// rebuildView recomputes the displayed rows, keeping the cursor on the same
+// row.
+func (m *Model) rebuildView() {
+	m.rebuildViewAt(m.cursorRow())
+}
+
+// rebuildViewAt recomputes the displayed rows and moves the cursor to the
+// data row with the given index. If that row is hidden, or anchor is -1,
+// the cursor moves to the header of the group it was in.
+func (m *Model) rebuildViewAt(anchor int) {
+	key, onGroup := m.SelectedGroup()
+	m.regroup(anchor, key, onGroup)
+}
+
+// regroup is rebuildViewAt for when the rows have been replaced since the
+// view was built, in which case the group the cursor was in is given, as
+// it was before the rows changed.
+func (m *Model) regroup(anchor int, key string, onGroup bool) {
+	if m.grouping == nil || m.source != nil {
+		m.view = nil
+		m.groups = nil
+		m.grandTotal = nil
+		if anchor >= 0 {
+			m.cursor = anchor
+		}
+		return
+	}
+
+	index := make(map[string]int)
+	m.groups = nil
+	for i, r := range m.rows {
+		k := cellValue(r, m.grouping.Column)
+		gi, ok := index[k]
+		if !ok {
+			gi = len(m.groups)
+			index[k] = gi
+			m.groups = append(m.groups, group{key: k})
+		}
+		m.groups[gi].rows = append(m.groups[gi].rows, i)
+	}
+	m.grandTotal = nil
+	if m.grouping.Footers {
+		for gi := range m.groups {
+			m.groups[gi].totals = m.aggregate(m.groups[gi].rows)
+		}
+	}
+	if m.grouping.GrandTotal {
+		m.grandTotal = m.aggregate(m.allRowIndices())
+	}
+	if anchor >= 0 && anchor < len(m.rows) {
+		key, onGroup = cellValue(m.rows[anchor], m.grouping.Column), true
+	}
+
+	view := make([]viewRow, 0, len(m.rows)+len(m.groups)*2+1) //nolint:mnd
+	cursor := -1
+	for gi, g := range m.groups {
+		if onGroup && g.key == key {
+			cursor = len(view)
+		}
+		view = append(view, viewRow{kind: groupHeaderRow, index: gi})
+		if !m.collapsed[g.key] {
+			for _, i := range g.rows {
+				if i == anchor {
+					cursor = len(view)
+				}
+				view = append(view, viewRow{kind: dataRow, index: i})
+			}
+		}
+		if m.grouping.Footers {
+			view = append(view, viewRow{kind: groupFooterRow, index: gi})
+		}
+	}
+	if m.grouping.GrandTotal {
+		view = append(view, viewRow{kind: grandTotalRow})
+	}
+	m.view = view
+
+	if cursor >= 0 {
+		m.cursor = cursor
+	}
+	m.cursor = clamp(m.cursor, 0, len(m.view)-1)
+	m.landCursor(1)
+}
+
+// landCursor moves the cursor in the given direction, and failing that the
+// opposite one, until it's on a row it can rest on.
+func (m *Model) landCursor(dir int) {
+	if m.view == nil {
+		return
+	}
+	for _, d := range []int{dir, -dir} {
+		for i := m.cursor; i >= 0 && i < len(m.view); i += d {
+			if m.canLand(i) {
+				m.cursor = i
+				return
+			}
+		}
+	}
+	// Every group is collapsed and headers are skipped, so settle on a
+	// header after all.
+	for i := m.cursor; i >= 0; i-- {
+		if m.view[i].kind == groupHeaderRow {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+func (m Model) canLand(v int) bool {
+	switch m.view[v].kind { //nolint:exhaustive
+	case dataRow:
+		return true
+	case groupHeaderRow:
+		return !m.grouping.SkipHeaders
+	}
+	return false
+}
+
+// renderViewRow renders a displayed row that isn't a data row.
+func (m Model) renderViewRow(v int, visible []int) string {
+	vr := m.view[v]
+
+	if vr.kind == groupHeaderRow {
+		g := m.groups[vr.index]
+		glyph := "▾"
+		if m.collapsed[g.key] {
+			glyph = "▸"
+		}
+		width := 0
+		for _, i := range visible {
+			width += m.colWidth(i) + m.styles.Cell.GetHorizontalFrameSize()
+		}
+		width = max(width-m.styles.Cell.GetHorizontalFrameSize(), 0)
+		text := glyph + " " + g.key + " (" + strconv.Itoa(len(g.rows)) + ")"
+		style := lipgloss.NewStyle().Width(width).MaxWidth(width).Inline(true)
+		row := m.styles.Cell.Render(m.styles.GroupHeader.Render(style.Render(text)))
+		if v == m.cursor {
+			return m.styles.Selected.Render(row)
+		}
+		return row
+	}
+
+	totals := m.grandTotal
+	if vr.kind == groupFooterRow {
+		totals = m.groups[vr.index].totals
+	}
+	s := make([]string, 0, len(visible))
+	for _, i := range visible {
+		s = append(s, m.styles.Cell.Render(m.styles.GroupFooter.Render(m.renderCell(i, totals[i]))))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, s...)
+}
+
+// aggregate computes the footer values of the given rows by column.
+func (m Model) aggregate(rows []int) map[int]string {
+	totals := make(map[int]string, len(m.grouping.Aggregators))
+	for col, agg := range m.grouping.Aggregators {
+		if agg == nil {
+			continue
+		}
+		values := make([]string, len(rows))
+		for j, r := range rows {
+			values[j] = cellValue(m.rows[r], col)
+		}
+		totals[col] = agg(values)
+	}
+	return totals
+}
+
+func (m Model) allRowIndices() []int {
+	rows := make([]int, len(m.rows))
+	for i := range rows {
+		rows[i] = i
+	}
+	return rows
+}
@@ -0,0 +1,214 @@
+package table
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// HeaderClickedMsg is sent when a column header is clicked. If header sorting
+// is enabled the rows have already been sorted; see SortColumn.
+type HeaderClickedMsg struct {
+	Col int
+}
+
+// ColumnResizedMsg is sent when a column has been resized by dragging its
+// border.
+type ColumnResizedMsg struct {
+	Col   int
+	Width int
+}
+
+// WithPosition sets the position of the table's top-left corner on screen.
+// It's used to translate the coordinates of mouse events.
+func WithPosition(x, y int) Option {
+	return func(m *Model) {
+		m.posX, m.posY = x, y
+	}
+}
+
+// WithHeaderSort enables or disables sorting the rows when a header is
+// clicked. Clicking the same header again reverses the order.
+func WithHeaderSort(v bool) Option {
+	return func(m *Model) {
+		m.headerSort = v
+	}
+}
+
+// SetPosition sets the position of the table's top-left corner on screen.
+// It's used to translate the coordinates of mouse events.
+func (m *Model) SetPosition(x, y int) {
+	m.posX, m.posY = x, y
+}
+
+// SetHeaderSort enables or disables sorting the rows when a header is
+// clicked.
+func (m *Model) SetHeaderSort(v bool) {
+	m.headerSort = v
+}
+
+// updateMouse handles mouse events: wheel scrolling, selecting rows and
+// cells, clicking headers and resizing columns.
+func (m Model) updateMouse(msg tea.MouseMsg) (Model, tea.Cmd) {
+	x, y := msg.X-m.posX, msg.Y-m.posY
+
+	if m.resizing {
+		switch msg.Action { //nolint:exhaustive
+		case tea.MouseActionMotion:
+			m.resizeColumn(m.resizeCol, x)
+			return m, nil
+		case tea.MouseActionRelease:
+			m.resizing = false
+			col, width := m.resizeCol, m.cols[m.resizeCol].Width
+			return m, func() tea.Msg {
+				return ColumnResizedMsg{Col: col, Width: width}
+			}
+		}
+	}
+
+	if msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+
+	switch msg.Button { //nolint:exhaustive
+	case tea.MouseButtonWheelUp:
+		if !m.viewport.MouseWheelEnabled {
+			break
+		}
+		if msg.Shift {
+			m.ScrollLeft(1)
+		} else {
+			m.MoveUp(m.viewport.MouseWheelDelta)
+		}
+	case tea.MouseButtonWheelDown:
+		if !m.viewport.MouseWheelEnabled {
+			break
+		}
+		if msg.Shift {
+			m.ScrollRight(1)
+		} else {
+			m.MoveDown(m.viewport.MouseWheelDelta)
+		}
+	case tea.MouseButtonWheelLeft:
+		if m.viewport.MouseWheelEnabled {
+			m.ScrollLeft(1)
+		}
+	case tea.MouseButtonWheelRight:
+		if m.viewport.MouseWheelEnabled {
+			m.ScrollRight(1)
+		}
+	case tea.MouseButtonLeft:
+		if y < 0 || x < 0 {
+			break
+		}
+		if y < lipgloss.Height(m.headersView()) {
+			return m.clickHeader(x)
+		}
+		m.clickRow(msg, x, y)
+	}
+
+	return m, nil
+}
+
+// clickHeader handles a click on the header row at the given x offset.
+func (m *Model) clickHeader(x int) (Model, tea.Cmd) {
+	col, border := m.columnAt(x, m.styles.Header)
+	if col < 0 {
+		return *m, nil
+	}
+	if border {
+		m.resizing = true
+		m.resizeCol = col
+		return *m, nil
+	}
+
+	if m.headerSort {
+		desc := m.sorted && m.sortCol == col && !m.sortDesc
+		m.SortBy(col, desc)
+	}
+	return *m, func() tea.Msg {
+		return HeaderClickedMsg{Col: col}
+	}
+}
+
+// clickRow moves the cursor to the row, and cell, at the given offset. With
+// multi-selection enabled, ctrl+click toggles the row and shift+click
+// selects every row up to the cursor.
+func (m *Model) clickRow(msg tea.MouseMsg, x, y int) {
+	line := y - lipgloss.Height(m.headersView()) + m.viewport.YOffset
+	if line >= m.viewport.YOffset+m.viewport.Height {
+		return
+	}
+	rowHeight := 1 + m.styles.Cell.GetVerticalFrameSize()
+	row := m.start + line/rowHeight
+	if row < m.start || row >= m.end {
+		return
+	}
+
+	switch {
+	case m.multiSelect && msg.Ctrl:
+		m.ToggleSelect(m.dataIndex(row))
+	case m.multiSelect && msg.Shift:
+		for v := min(m.cursor, row); v <= max(m.cursor, row); v++ {
+			m.Select(m.dataIndex(v))
+		}
+	}
+
+	if row < m.cursor {
+		m.MoveUp(m.cursor - row)
+	} else if row > m.cursor {
+		m.MoveDown(row - m.cursor)
+	}
+
+	if m.cellNavigation {
+		if col, _ := m.columnAt(x, m.styles.Cell); col >= 0 {
+			m.SetColumnCursor(col)
+		}
+	}
+}
+
+// columnAt returns the index of the visible column at the given x offset,
+// given the style of its cells. border is set if the offset is on the gap
+// between two columns, i.e. the right padding of a column or the left padding
+// of the one after it, which acts as the resize handle of the column on the
+// left. The returned column is then the one being resized.
+func (m Model) columnAt(x int, style lipgloss.Style) (col int, border bool) {
+	frame := style.GetHorizontalFrameSize()
+	leftGap := style.GetPaddingLeft() + style.GetMarginLeft() + style.GetBorderLeftSize()
+	rightGap := style.GetPaddingRight() + style.GetMarginRight() + style.GetBorderRightSize()
+
+	left, prev := 0, -1
+	for _, i := range m.visibleColumns() {
+		right := left + m.colWidth(i) + frame
+		if x < right {
+			switch {
+			case x >= right-rightGap:
+				return i, true
+			case x < left+leftGap && prev >= 0:
+				return prev, true
+			}
+			return i, false
+		}
+		left, prev = right, i
+	}
+	return -1, false
+}
+
+// resizeColumn sets the width of column i so that its right edge ends at the
+// given x offset. The column becomes fixed width.
+func (m *Model) resizeColumn(i, x int) {
+	left := 0
+	for _, j := range m.visibleColumns() {
+		if j == i {
+			break
+		}
+		left += m.colWidth(j) + m.styles.Header.GetHorizontalFrameSize()
+	}
+
+	width := max(x-left-m.styles.Header.GetHorizontalFrameSize()+1, 1)
+	cols := append([]Column(nil), m.cols...)
+	cols[i].Width = width
+	cols[i].MinWidth, cols[i].MaxWidth = 0, 0
+	cols[i].Flex, cols[i].Fit = 0, false
+	m.cols = cols
+	m.UpdateViewport()
+}
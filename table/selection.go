@@ -0,0 +1,126 @@
+package table
+
+import "sort"
+
+// MultiSelect returns whether multi-row selection is enabled.
+func (m Model) MultiSelect() bool {
+	return m.multiSelect
+}
+
+// SetMultiSelect enables or disables multi-row selection. Disabling it clears
+// the current selection.
+func (m *Model) SetMultiSelect(v bool) {
+	m.multiSelect = v
+	if !v {
+		m.selected, m.selectInverted = nil, false
+	}
+	m.UpdateViewport()
+}
+
+// IsSelected returns whether the row at the given index is part of the
+// multi-row selection.
+func (m Model) IsSelected(i int) bool {
+	_, ok := m.selected[i]
+	if m.selectInverted {
+		return !ok && i >= 0 && i < m.rowCount()
+	}
+	return ok
+}
+
+// Select adds the row at the given index to the selection.
+func (m *Model) Select(i int) {
+	if i < 0 || i >= m.rowCount() {
+		return
+	}
+	m.setSelected(i, true)
+	m.UpdateViewport()
+}
+
+// Deselect removes the row at the given index from the selection.
+func (m *Model) Deselect(i int) {
+	if i < 0 || i >= m.rowCount() {
+		return
+	}
+	m.setSelected(i, false)
+	m.UpdateViewport()
+}
+
+// ToggleSelect toggles the selection state of the row at the given index.
+func (m *Model) ToggleSelect(i int) {
+	if m.IsSelected(i) {
+		m.Deselect(i)
+		return
+	}
+	m.Select(i)
+}
+
+// SelectRange adds all rows between from and to, inclusive, to the
+// selection. The bounds may be given in any order.
+func (m *Model) SelectRange(from, to int) {
+	if from > to {
+		from, to = to, from
+	}
+	for i := max(from, 0); i <= min(to, m.rowCount()-1); i++ {
+		m.setSelected(i, true)
+	}
+	m.UpdateViewport()
+}
+
+// SelectAll selects every row.
+func (m *Model) SelectAll() {
+	m.selected, m.selectInverted = nil, true
+	m.UpdateViewport()
+}
+
+// SelectNone clears the selection.
+func (m *Model) SelectNone() {
+	m.selected, m.selectInverted = nil, false
+	m.UpdateViewport()
+}
+
+// InvertSelection selects every row that isn't selected and deselects every
+// row that is.
+func (m *Model) InvertSelection() {
+	m.selectInverted = !m.selectInverted
+	m.UpdateViewport()
+}
+
+// SelectedIndices returns the indices of the selected rows in ascending
+// order.
+func (m Model) SelectedIndices() []int {
+	if m.selectInverted {
+		indices := make([]int, 0, max(m.rowCount()-len(m.selected), 0))
+		for i := range m.rowCount() {
+			if _, ok := m.selected[i]; !ok {
+				indices = append(indices, i)
+			}
+		}
+		return indices
+	}
+
+	indices := make([]int, 0, len(m.selected))
+	for i := range m.selected {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// SelectedRows returns the selected rows in table order. Rows of a data
+// source that aren't cached are fetched from it, except with asynchronous
+// loading, where they're nil.
+func (m Model) SelectedRows() []Row {
+	return m.fetchRows(m.SelectedIndices())
+}
+
+// setSelected adds the row at index i to the selection or removes it.
+func (m *Model) setSelected(i int, v bool) {
+	if v == m.selectInverted {
+		delete(m.selected, i)
+		return
+	}
+	if m.selected == nil {
+		m.selected = make(map[int]struct{})
+	}
+	m.selected[i] = struct{}{}
+}
@@ -0,0 +1,115 @@
+package table
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// SortBy sorts the rows by the values of the given column, in descending
+// order if desc is set. Numeric values are compared as numbers and sort before
+// other values. The cursor and selection stay on the same rows. In tree mode
+// siblings are sorted at every level. Rows provided by a data source can't be
+// sorted by the table.
+func (m *Model) SortBy(col int, desc bool) {
+	if m.source != nil || col < 0 || col >= len(m.cols) {
+		return
+	}
+
+	if m.tree != nil {
+		m.sorted = true
+		m.sortCol = col
+		m.sortDesc = desc
+		m.flattenTree()
+		m.UpdateViewport()
+		return
+	}
+
+	perm := make([]int, len(m.rows))
+	for i := range perm {
+		perm[i] = i
+	}
+	sort.SliceStable(perm, func(a, b int) bool {
+		x, y := cellValue(m.rows[perm[a]], col), cellValue(m.rows[perm[b]], col)
+		if desc {
+			return lessValue(y, x)
+		}
+		return lessValue(x, y)
+	})
+	anchor := m.applyOrder(perm)
+	m.rebuildViewAt(anchor)
+
+	m.sorted = true
+	m.sortCol = col
+	m.sortDesc = desc
+	m.UpdateViewport()
+}
+
+// SortColumn returns the column the rows are sorted by and whether they're
+// sorted in descending order. ok is false if the rows aren't sorted.
+func (m Model) SortColumn() (col int, desc bool, ok bool) {
+	return m.sortCol, m.sortDesc, m.sorted
+}
+
+// ClearSort forgets the current sort order. Rows stay in their current
+// order, except in tree mode, where the nodes' own order is restored.
+func (m *Model) ClearSort() {
+	m.sorted = false
+	m.sortCol = 0
+	m.sortDesc = false
+	m.flattenTree()
+	m.UpdateViewport()
+}
+
+// applyOrder reorders the rows so that the row at index perm[i] ends up at
+// index i, keeping the selection on the same rows. It returns the new index
+// of the row under the cursor, or -1 if the cursor isn't on a row.
+func (m *Model) applyOrder(perm []int) int {
+	rows := make([]Row, len(perm))
+	var selected map[int]struct{}
+	if len(m.selected) > 0 {
+		selected = make(map[int]struct{}, len(m.selected))
+	}
+	cursor, anchor := m.cursorRow(), -1
+	for i, old := range perm {
+		rows[i] = m.rows[old]
+		if _, ok := m.selected[old]; ok {
+			selected[i] = struct{}{}
+		}
+		if old == cursor {
+			anchor = i
+		}
+	}
+	m.rows = rows
+	m.selected = selected
+	return anchor
+}
+
+func cellValue(r Row, col int) string {
+	if col < len(r) {
+		return r[col]
+	}
+	return ""
+}
+
+// lessValue compares two cell values. Numbers are compared numerically and
+// come before any other values, which are compared as strings. This keeps the
+// order consistent when a column mixes both.
+func lessValue(x, y string) bool {
+	a, xNum := number(x)
+	b, yNum := number(y)
+	switch {
+	case xNum && yNum:
+		return a < b
+	case xNum != yNum:
+		return xNum
+	}
+	return x < y
+}
+
+// number parses a cell value as a number. NaN isn't considered a number,
+// since it doesn't compare with anything.
+func number(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	return f, err == nil && !math.IsNaN(f)
+}
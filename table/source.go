@@ -0,0 +1,330 @@
+package table
+
+import (
+	"maps"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DataSource provides rows to the table on demand, so that only the rows
+// around the visible window need to be held in memory. Rows are fetched in
+// pages.
+type DataSource interface {
+	// Len returns the total number of rows. It's called frequently, so it
+	// should be cheap.
+	Len() int
+
+	// Rows returns the rows from start up to, but not including, end.
+	Rows(start, end int) []Row
+}
+
+const (
+	defaultPageSize = 100
+	maxCachedPages  = 16
+)
+
+// Internal ID management. Used during asynchronous loading to ensure that
+// pages are received only by the table and data source that requested them.
+var lastSourceID int64
+
+func nextSourceID() int {
+	return int(atomic.AddInt64(&lastSourceID, 1))
+}
+
+// pageLoadedMsg is sent when a page of rows has been loaded asynchronously.
+type pageLoadedMsg struct {
+	id   int
+	page int
+	rows []Row
+}
+
+// WithDataSource sets a data source the table fetches rows from instead of
+// using in-memory rows.
+func WithDataSource(ds DataSource) Option {
+	return func(m *Model) {
+		m.setDataSource(ds)
+	}
+}
+
+// WithAsyncLoading makes the table fetch pages of the given size from its
+// data source in a command rather than in the update loop. Rows that haven't
+// been loaded yet are rendered as placeholders. Since New can't return a
+// command, call LoadVisibleRows to load the first page.
+func WithAsyncLoading(pageSize int) Option {
+	return func(m *Model) {
+		m.async = true
+		m.pageSize = pageSize
+	}
+}
+
+// DataSource returns the table's data source, if any.
+func (m Model) DataSource() DataSource {
+	return m.source
+}
+
+// SetDataSource sets a data source the table fetches rows from, replacing
+// any in-memory rows and leaving tree mode. The returned command loads the
+// visible rows when asynchronous loading is enabled.
+func (m *Model) SetDataSource(ds DataSource) tea.Cmd {
+	m.setDataSource(ds)
+	m.cursor = clamp(m.cursor, 0, m.rowCount()-1)
+	m.UpdateViewport()
+	return m.LoadVisibleRows()
+}
+
+// ReloadRows discards every cached page so that rows are fetched from the
+// data source again, e.g. after its contents have changed.
+func (m *Model) ReloadRows() tea.Cmd {
+	if m.source == nil {
+		return nil
+	}
+	m.sourceID = nextSourceID()
+	m.pages = nil
+	m.loading = nil
+	m.fitWidths = nil
+	m.cursor = clamp(m.cursor, 0, m.rowCount()-1)
+	m.UpdateViewport()
+	return m.LoadVisibleRows()
+}
+
+// LoadVisibleRows returns a command loading the pages of the rows around the
+// cursor that aren't loaded or being loaded yet. It returns nil unless
+// asynchronous loading is enabled.
+func (m *Model) LoadVisibleRows() tea.Cmd {
+	if m.source == nil || !m.async {
+		return nil
+	}
+
+	var (
+		cmds    []tea.Cmd
+		loading map[int]struct{}
+	)
+	for _, page := range m.windowPages() {
+		if _, ok := m.pages[page]; ok {
+			continue
+		}
+		if _, ok := m.loading[page]; ok {
+			continue
+		}
+		if loading == nil {
+			loading = make(map[int]struct{}, len(m.loading)+1)
+			maps.Copy(loading, m.loading)
+			m.loading = loading
+		}
+		loading[page] = struct{}{}
+
+		ds, id, page := m.source, m.sourceID, page
+		start, end := m.pageBounds(page)
+		cmds = append(cmds, func() tea.Msg {
+			return pageLoadedMsg{id: id, page: page, rows: ds.Rows(start, end)}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *Model) setDataSource(ds DataSource) {
+	m.source = ds
+	m.tree, m.nodes, m.depths = nil, nil, nil
+	m.loadingNodes = nil
+	m.sourceID = nextSourceID()
+	m.rows = nil
+	m.pages = nil
+	m.loading = nil
+	m.selected = nil
+	m.selectInverted = false
+	m.sorted = false
+	m.fitWidths = nil
+	m.view = nil
+	m.groups = nil
+}
+
+// handlePageLoaded stores a page of rows loaded asynchronously.
+func (m *Model) handlePageLoaded(msg pageLoadedMsg) {
+	if msg.id != m.sourceID {
+		return
+	}
+	if _, ok := m.loading[msg.page]; ok {
+		m.loading = maps.Clone(m.loading)
+		delete(m.loading, msg.page)
+	}
+	pages := make(map[int][]Row, len(m.pages)+1)
+	maps.Copy(pages, m.pages)
+	pages[msg.page] = msg.rows
+	m.pages = pages
+	m.fitWidths = nil
+	m.evictPages()
+	m.UpdateViewport()
+}
+
+// rowCount returns the total number of rows.
+func (m Model) rowCount() int {
+	if m.source != nil {
+		return m.source.Len()
+	}
+	return len(m.rows)
+}
+
+// row returns the row at index i, or nil if it doesn't exist or hasn't been
+// loaded yet.
+func (m Model) row(i int) Row {
+	if m.source == nil {
+		if i < 0 || i >= len(m.rows) {
+			return nil
+		}
+		return m.rows[i]
+	}
+	if i < 0 {
+		return nil
+	}
+	page, ok := m.pages[i/m.pageLen()]
+	if !ok || i%m.pageLen() >= len(page) {
+		return nil
+	}
+	return page[i%m.pageLen()]
+}
+
+// fetchRows returns the rows at the given ascending indices. Rows of a data
+// source that aren't cached are queried from it, in runs of consecutive
+// indices, unless loading is asynchronous, in which case they're nil.
+func (m Model) fetchRows(indices []int) []Row {
+	rows := make([]Row, len(indices))
+	for j := 0; j < len(indices); j++ {
+		if rows[j] = m.row(indices[j]); rows[j] != nil || m.source == nil || m.async {
+			continue
+		}
+		// Gather the run of consecutive uncached rows starting here.
+		end := j + 1
+		for end < len(indices) && indices[end] == indices[end-1]+1 && m.row(indices[end]) == nil {
+			end++
+		}
+		copy(rows[j:end], m.source.Rows(indices[j], indices[end-1]+1))
+		j = end - 1
+	}
+	return rows
+}
+
+// setRow replaces the row at index i. For data sources only the cached copy
+// is updated.
+func (m *Model) setRow(i int, r Row) {
+	m.fitWidths = nil
+	if m.source == nil {
+		m.rows[i] = r
+		if i < len(m.nodes) {
+			m.nodes[i].Row = r
+		}
+		return
+	}
+	if page, ok := m.pages[i/m.pageLen()]; ok && i%m.pageLen() < len(page) {
+		page = append([]Row(nil), page...)
+		page[i%m.pageLen()] = r
+		m.pages = maps.Clone(m.pages)
+		m.pages[i/m.pageLen()] = page
+	}
+}
+
+// loadedRows calls fn for every row held in memory.
+func (m Model) loadedRows(fn func(Row)) {
+	if m.source == nil {
+		for _, r := range m.rows {
+			fn(r)
+		}
+		return
+	}
+	for _, page := range m.pages {
+		for _, r := range page {
+			fn(r)
+		}
+	}
+}
+
+// ensureRows synchronously fetches the pages of the rows around the cursor
+// that aren't cached yet. It does nothing for asynchronous loading.
+func (m *Model) ensureRows() {
+	if m.source == nil || m.async {
+		return
+	}
+	var pages map[int][]Row
+	for _, page := range m.windowPages() {
+		if _, ok := m.pages[page]; ok {
+			continue
+		}
+		if pages == nil {
+			pages = make(map[int][]Row, len(m.pages)+1)
+			maps.Copy(pages, m.pages)
+			m.pages = pages
+		}
+		start, end := m.pageBounds(page)
+		pages[page] = m.source.Rows(start, end)
+		m.fitWidths = nil
+	}
+	m.evictPages()
+}
+
+// evictPages drops the cached pages farthest from the cursor once there are
+// more than maxCachedPages of them. Pages in the rendered window are kept.
+func (m *Model) evictPages() {
+	window := m.windowPages()
+	limit := max(maxCachedPages, len(window))
+	if len(m.pages) <= limit {
+		return
+	}
+
+	keep := make(map[int]struct{}, len(window))
+	for _, page := range window {
+		keep[page] = struct{}{}
+	}
+	m.pages = maps.Clone(m.pages)
+	current := m.cursor / m.pageLen()
+	for len(m.pages) > limit {
+		farthest, distance := -1, -1
+		for page := range m.pages {
+			if _, ok := keep[page]; ok {
+				continue
+			}
+			if d := abs(page - current); d > distance {
+				farthest, distance = page, d
+			}
+		}
+		if farthest < 0 {
+			return
+		}
+		delete(m.pages, farthest)
+		m.fitWidths = nil
+	}
+}
+
+// windowPages returns the pages covering the rows rendered around the
+// cursor.
+func (m Model) windowPages() []int {
+	start := max(m.cursor-m.viewport.Height, 0)
+	end := min(m.cursor+m.viewport.Height, m.rowCount())
+	if end <= start {
+		return nil
+	}
+	pages := make([]int, 0, (end-start)/m.pageLen()+1)
+	for page := start / m.pageLen(); page <= (end-1)/m.pageLen(); page++ {
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// pageBounds returns the range of row indices covered by a page.
+func (m Model) pageBounds(page int) (start, end int) {
+	start = page * m.pageLen()
+	return start, min(start+m.pageLen(), m.rowCount())
+}
+
+func (m Model) pageLen() int {
+	if m.pageSize <= 0 {
+		return defaultPageSize
+	}
+	return m.pageSize
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -6,10 +6,10 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/mattn/go-runewidth"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 )
 
@@ -24,6 +24,80 @@ type Model struct {
 	focus  bool
 	styles Styles
 
+	// multiSelect enables marking several rows at once. The indices of the
+	// marked rows are kept in selected or, with selectInverted set, the
+	// indices of the rows that aren't marked, so that selecting every row
+	// doesn't take an entry per row.
+	multiSelect    bool
+	selected       map[int]struct{}
+	selectInverted bool
+
+	// widths holds the resolved column widths and fitWidths caches the
+	// content widths of Fit columns until the rows or columns change. frozen
+	// is the number of leading columns that don't scroll horizontally and
+	// colOffset is the number of scrollable columns scrolled past.
+	widths    []int
+	fitWidths []int
+	frozen    int
+	colOffset int
+
+	// cellNavigation moves a column cursor across the cells of the selected
+	// row. When editable is set, the selected cell can be edited in place
+	// with input.
+	cellNavigation bool
+	colCursor      int
+	styleFunc      StyleFunc
+	editable       bool
+	editing        bool
+	editErr        error
+	validate       ValidateFunc
+	input          textinput.Model
+
+	// source provides rows lazily instead of rows. Its rows are cached in
+	// pages of pageSize rows; with async set, pages are fetched in commands
+	// and the pages being fetched are tracked in loading. Both maps are
+	// replaced rather than modified, since copies of the model share them.
+	source   DataSource
+	sourceID int
+	async    bool
+	pageSize int
+	pages    map[int][]Row
+	loading  map[int]struct{}
+
+	// Sorting state, and whether clicking a header sorts by its column.
+	sorted     bool
+	sortCol    int
+	sortDesc   bool
+	headerSort bool
+
+	// posX and posY locate the table on screen for mouse hit-testing. While
+	// a column border is being dragged, resizing is set and resizeCol holds
+	// the column being resized.
+	posX      int
+	posY      int
+	resizing  bool
+	resizeCol int
+
+	// grouping groups the rows under collapsible headers. When set, view
+	// holds the displayed rows, including headers and footers, and the
+	// cursor indexes into it rather than into rows. grandTotal holds the
+	// aggregated values of every row by column.
+	grouping   *Grouping
+	groups     []group
+	collapsed  map[string]bool
+	view       []viewRow
+	grandTotal map[int]string
+
+	// tree holds the root nodes in tree mode. rows then holds the visible
+	// nodes, which are kept in nodes along with their depths. The nodes
+	// whose children are being loaded are tracked in loadingNodes, which is
+	// replaced rather than modified, since copies of the model share it.
+	tree         []*Node
+	nodes        []*Node
+	depths       []int
+	loadChildren LoadChildrenFunc
+	loadingNodes map[*Node]struct{}
+
 	viewport viewport.Model
 	start    int
 	end      int
@@ -33,9 +107,22 @@ type Model struct {
 type Row []string
 
 // Column defines the table structure.
+//
+// By default a column has a fixed Width. Setting Fit sizes the column to its
+// widest value instead, and setting Flex shares the table width left over by
+// the other columns between all flexible columns, proportionally to their
+// weight. MinWidth and MaxWidth bound the resolved width in every mode.
 type Column struct {
 	Title string
 	Width int
+
+	MinWidth int
+	MaxWidth int
+	Flex     int
+	Fit      bool
+
+	// Align sets the horizontal alignment of the title and cells.
+	Align lipgloss.Position
 }
 
 // KeyMap defines keybindings. It satisfies to the help.KeyMap interface, which
@@ -49,6 +136,36 @@ type KeyMap struct {
 	HalfPageDown key.Binding
 	GotoTop      key.Binding
 	GotoBottom   key.Binding
+	ScrollLeft   key.Binding
+	ScrollRight  key.Binding
+
+	// Keybindings used to move between and edit cells. These only take
+	// effect while cell navigation and editing are turned on.
+	CellLeft   key.Binding
+	CellRight  key.Binding
+	Edit       key.Binding
+	CommitEdit key.Binding
+	CancelEdit key.Binding
+
+	// Keybinding used to collapse and expand the group under the cursor.
+	// It only takes effect while rows are grouped.
+	ToggleGroup key.Binding
+
+	// Keybindings used to expand and collapse nodes in tree mode. These only
+	// take effect while the table is in tree mode, and on nodes that can be
+	// expanded or collapsed; elsewhere, keys shared with ScrollLeft and
+	// ScrollRight scroll the table.
+	Expand   key.Binding
+	Collapse key.Binding
+
+	// Keybindings used for multi-row selection. These only take effect while
+	// multi-selection is turned on with WithMultiSelect or SetMultiSelect.
+	ToggleSelect    key.Binding
+	SelectUp        key.Binding
+	SelectDown      key.Binding
+	SelectAll       key.Binding
+	SelectNone      key.Binding
+	InvertSelection key.Binding
 }
 
 // ShortHelp implements the KeyMap interface.
@@ -61,6 +178,11 @@ func (km KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{km.LineUp, km.LineDown, km.GotoTop, km.GotoBottom},
 		{km.PageUp, km.PageDown, km.HalfPageUp, km.HalfPageDown},
+		{km.ScrollLeft, km.ScrollRight, km.CellLeft, km.CellRight},
+		{km.Edit, km.CommitEdit, km.CancelEdit, km.ToggleGroup},
+		{km.Expand, km.Collapse},
+		{km.ToggleSelect, km.SelectUp, km.SelectDown},
+		{km.SelectAll, km.SelectNone, km.InvertSelection},
 	}
 }
 
@@ -100,6 +222,70 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("end", "G"),
 			key.WithHelp("G/end", "go to end"),
 		),
+		ScrollLeft: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←/h", "scroll left"),
+		),
+		ScrollRight: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("→/l", "scroll right"),
+		),
+		CellLeft: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←/h", "left"),
+		),
+		CellRight: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("→/l", "right"),
+		),
+		Edit: key.NewBinding(
+			key.WithKeys("enter", "e"),
+			key.WithHelp("enter/e", "edit"),
+		),
+		CommitEdit: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "save"),
+		),
+		CancelEdit: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+		ToggleGroup: key.NewBinding(
+			key.WithKeys("enter", "o"),
+			key.WithHelp("enter/o", "toggle group"),
+		),
+		Expand: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("→/l", "expand"),
+		),
+		Collapse: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←/h", "collapse"),
+		),
+		ToggleSelect: key.NewBinding(
+			key.WithKeys(spacebar, "x"),
+			key.WithHelp("space/x", "toggle select"),
+		),
+		SelectUp: key.NewBinding(
+			key.WithKeys("shift+up", "K"),
+			key.WithHelp("shift+↑/K", "select up"),
+		),
+		SelectDown: key.NewBinding(
+			key.WithKeys("shift+down", "J"),
+			key.WithHelp("shift+↓/J", "select down"),
+		),
+		SelectAll: key.NewBinding(
+			key.WithKeys("a", "ctrl+a"),
+			key.WithHelp("a", "select all"),
+		),
+		SelectNone: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "select none"),
+		),
+		InvertSelection: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "invert selection"),
+		),
 	}
 }
 
@@ -109,6 +295,22 @@ type Styles struct {
 	Header   lipgloss.Style
 	Cell     lipgloss.Style
 	Selected lipgloss.Style
+
+	// Marked is applied to rows that are part of the multi-row selection.
+	Marked lipgloss.Style
+
+	// SelectedCell is applied to the selected cell when cell navigation is
+	// enabled.
+	SelectedCell lipgloss.Style
+
+	// Placeholder is applied to rows that are still being loaded from a
+	// data source.
+	Placeholder lipgloss.Style
+
+	// GroupHeader and GroupFooter are applied to the header and footer rows
+	// of groups. GroupFooter is also applied to the grand total.
+	GroupHeader lipgloss.Style
+	GroupFooter lipgloss.Style
 }
 
 // DefaultStyles returns a set of default style definitions for this table.
@@ -117,6 +319,12 @@ func DefaultStyles() Styles {
 		Selected: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212")),
 		Header:   lipgloss.NewStyle().Bold(true).Padding(0, 1),
 		Cell:     lipgloss.NewStyle().Padding(0, 1),
+		Marked:   lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57")),
+
+		SelectedCell: lipgloss.NewStyle().Reverse(true),
+		Placeholder:  lipgloss.NewStyle().Faint(true),
+		GroupHeader:  lipgloss.NewStyle().Bold(true),
+		GroupFooter:  lipgloss.NewStyle().Italic(true),
 	}
 }
 
@@ -146,6 +354,7 @@ func New(opts ...Option) Model {
 		opt(&m)
 	}
 
+	m.rebuildViewAt(-1)
 	m.UpdateViewport()
 
 	return m
@@ -162,6 +371,8 @@ func WithColumns(cols []Column) Option {
 func WithRows(rows []Row) Option {
 	return func(m *Model) {
 		m.rows = rows
+		m.source = nil
+		m.tree, m.nodes, m.depths = nil, nil, nil
 	}
 }
 
@@ -200,35 +411,101 @@ func WithKeyMap(km KeyMap) Option {
 	}
 }
 
+// WithFrozenColumns freezes the first n columns so that they stay visible
+// when scrolling horizontally.
+func WithFrozenColumns(n int) Option {
+	return func(m *Model) {
+		m.frozen = max(n, 0)
+	}
+}
+
+// WithMultiSelect enables or disables multi-row selection.
+func WithMultiSelect(v bool) Option {
+	return func(m *Model) {
+		m.multiSelect = v
+	}
+}
+
 // Update is the Bubble Tea update loop.
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case pageLoadedMsg:
+		m.handlePageLoaded(msg)
+		return m, m.LoadVisibleRows()
+	case ChildrenLoadedMsg:
+		m.handleChildrenLoaded(msg)
+		return m, nil
+	}
+
 	if !m.focus {
 		return m, nil
 	}
 
+	if m.editing {
+		return m.updateEditing(msg)
+	}
+
 	switch msg := msg.(type) {
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		m, cmd = m.updateMouse(msg)
+		return m, tea.Batch(cmd, m.LoadVisibleRows())
 	case tea.KeyMsg:
+		km := m.keys()
 		switch {
-		case key.Matches(msg, m.KeyMap.LineUp):
+		case m.onGroupHeader() && key.Matches(msg, km.ToggleGroup):
+			k, _ := m.SelectedGroup()
+			m.ToggleGroup(k)
+		case key.Matches(msg, km.Edit):
+			return m, m.StartEdit()
+		case m.canExpandSelected() && key.Matches(msg, km.Expand):
+			return m, m.expandSelected()
+		case m.canCollapseSelected() && key.Matches(msg, km.Collapse):
+			m.collapseSelected()
+		case key.Matches(msg, km.ToggleSelect):
+			m.ToggleSelect(m.cursorRow())
+		case key.Matches(msg, km.SelectUp):
+			m.Select(m.cursorRow())
 			m.MoveUp(1)
-		case key.Matches(msg, m.KeyMap.LineDown):
+			m.Select(m.cursorRow())
+		case key.Matches(msg, km.SelectDown):
+			m.Select(m.cursorRow())
 			m.MoveDown(1)
-		case key.Matches(msg, m.KeyMap.PageUp):
+			m.Select(m.cursorRow())
+		case key.Matches(msg, km.SelectAll):
+			m.SelectAll()
+		case key.Matches(msg, km.SelectNone):
+			m.SelectNone()
+		case key.Matches(msg, km.InvertSelection):
+			m.InvertSelection()
+		case key.Matches(msg, km.LineUp):
+			m.MoveUp(1)
+		case key.Matches(msg, km.LineDown):
+			m.MoveDown(1)
+		case key.Matches(msg, km.PageUp):
 			m.MoveUp(m.viewport.Height)
-		case key.Matches(msg, m.KeyMap.PageDown):
+		case key.Matches(msg, km.PageDown):
 			m.MoveDown(m.viewport.Height)
-		case key.Matches(msg, m.KeyMap.HalfPageUp):
+		case key.Matches(msg, km.HalfPageUp):
 			m.MoveUp(m.viewport.Height / 2) //nolint:mnd
-		case key.Matches(msg, m.KeyMap.HalfPageDown):
+		case key.Matches(msg, km.HalfPageDown):
 			m.MoveDown(m.viewport.Height / 2) //nolint:mnd
-		case key.Matches(msg, m.KeyMap.GotoTop):
+		case key.Matches(msg, km.GotoTop):
 			m.GotoTop()
-		case key.Matches(msg, m.KeyMap.GotoBottom):
+		case key.Matches(msg, km.GotoBottom):
 			m.GotoBottom()
+		case key.Matches(msg, km.CellLeft):
+			m.MoveLeft(1)
+		case key.Matches(msg, km.CellRight):
+			m.MoveRight(1)
+		case key.Matches(msg, km.ScrollLeft):
+			m.ScrollLeft(1)
+		case key.Matches(msg, km.ScrollRight):
+			m.ScrollRight(1)
 		}
 	}
 
-	return m, nil
+	return m, m.LoadVisibleRows()
 }
 
 // Focused returns the focus state of the table.
@@ -258,13 +535,16 @@ func (m Model) View() string {
 // Note that this view is not rendered by default and you must call it
 // manually in your application, where applicable.
 func (m Model) HelpView() string {
-	return m.Help.View(m.KeyMap)
+	return m.Help.View(m.keys())
 }
 
 // UpdateViewport updates the list content based on the previously defined
 // columns and rows.
 func (m *Model) UpdateViewport() {
-	renderedRows := make([]string, 0, len(m.rows))
+	m.ensureRows()
+	m.resolveWidths()
+
+	renderedRows := make([]string, 0, m.viewport.Height*2) //nolint:mnd
 
 	// Render only rows from: m.cursor-m.viewport.Height to: m.cursor+m.viewport.Height
 	// Constant runtime, independent of number of rows in a table.
@@ -274,9 +554,10 @@ func (m *Model) UpdateViewport() {
 	} else {
 		m.start = 0
 	}
-	m.end = clamp(m.cursor+m.viewport.Height, m.cursor, len(m.rows))
+	m.end = clamp(m.cursor+m.viewport.Height, m.cursor, m.viewLen())
+	visible := m.visibleColumns()
 	for i := m.start; i < m.end; i++ {
-		renderedRows = append(renderedRows, m.renderRow(i))
+		renderedRows = append(renderedRows, m.renderRow(i, visible))
 	}
 
 	m.viewport.SetContent(
@@ -284,17 +565,21 @@ func (m *Model) UpdateViewport() {
 	)
 }
 
-// SelectedRow returns the selected row.
+// SelectedRow returns the selected row. It returns nil if the cursor is on a
+// group header.
 // You can cast it to your own implementation.
 func (m Model) SelectedRow() Row {
-	if m.cursor < 0 || m.cursor >= len(m.rows) {
-		return nil
-	}
+	return m.row(m.cursorRow())
+}
 
-	return m.rows[m.cursor]
+// RowCount returns the total number of rows, including the rows of a data
+// source that haven't been loaded.
+func (m Model) RowCount() int {
+	return m.rowCount()
 }
 
-// Rows returns the current rows.
+// Rows returns the current rows. It returns nil when rows are provided by a
+// data source.
 func (m Model) Rows() []Row {
 	return m.rows
 }
@@ -304,24 +589,53 @@ func (m Model) Columns() []Column {
 	return m.cols
 }
 
-// SetRows sets a new rows state.
+// SetRows sets a new rows state, replacing any data source and leaving tree
+// mode. Selected rows that no longer exist are deselected.
 func (m *Model) SetRows(r []Row) {
+	if m.tree != nil {
+		m.tree, m.nodes, m.depths = nil, nil, nil
+		m.loadingNodes = nil
+	}
+
+	rows, anchor := m.rowCount(), m.cursorRow()
+	key, onGroup := m.SelectedGroup()
 	m.rows = r
+	m.source = nil
+	m.pages = nil
+	m.loading = nil
+	m.sorted = false
+	m.fitWidths = nil
 
 	if m.cursor > len(m.rows)-1 {
 		m.cursor = len(m.rows) - 1
 	}
 
+	for i := range m.selected {
+		if i >= len(m.rows) {
+			delete(m.selected, i)
+		}
+	}
+	if m.selectInverted {
+		// Rows that didn't exist before aren't selected.
+		for i := rows; i < len(m.rows); i++ {
+			m.setSelected(i, false)
+		}
+	}
+
+	m.regroup(anchor, key, onGroup)
+
 	m.UpdateViewport()
 }
 
 // SetColumns sets a new columns state.
 func (m *Model) SetColumns(c []Column) {
 	m.cols = c
+	m.fitWidths = nil
 	m.UpdateViewport()
 }
 
-// SetWidth sets the width of the viewport of the table.
+// SetWidth sets the width of the viewport of the table. Flexible column
+// widths are resolved against it.
 func (m *Model) SetWidth(w int) {
 	m.viewport.Width = w
 	m.UpdateViewport()
@@ -343,21 +657,24 @@ func (m Model) Width() int {
 	return m.viewport.Width
 }
 
-// Cursor returns the index of the selected row.
+// Cursor returns the index of the selected row. When rows are grouped, it's
+// the index among the displayed rows, including group headers and footers.
 func (m Model) Cursor() int {
 	return m.cursor
 }
 
 // SetCursor sets the cursor position in the table.
 func (m *Model) SetCursor(n int) {
-	m.cursor = clamp(n, 0, len(m.rows)-1)
+	m.cursor = clamp(n, 0, m.viewLen()-1)
+	m.landCursor(1)
 	m.UpdateViewport()
 }
 
 // MoveUp moves the selection up by any number of rows.
 // It can not go above the first row.
 func (m *Model) MoveUp(n int) {
-	m.cursor = clamp(m.cursor-n, 0, len(m.rows)-1)
+	m.cursor = clamp(m.cursor-n, 0, m.viewLen()-1)
+	m.landCursor(-1)
 	switch {
 	case m.start == 0:
 		m.viewport.SetYOffset(clamp(m.viewport.YOffset, 0, m.cursor))
@@ -372,11 +689,12 @@ func (m *Model) MoveUp(n int) {
 // MoveDown moves the selection down by any number of rows.
 // It can not go below the last row.
 func (m *Model) MoveDown(n int) {
-	m.cursor = clamp(m.cursor+n, 0, len(m.rows)-1)
+	m.cursor = clamp(m.cursor+n, 0, m.viewLen()-1)
+	m.landCursor(1)
 	m.UpdateViewport()
 
 	switch {
-	case m.end == len(m.rows) && m.viewport.YOffset > 0:
+	case m.end == m.viewLen() && m.viewport.YOffset > 0:
 		m.viewport.SetYOffset(clamp(m.viewport.YOffset-n, 1, m.viewport.Height))
 	case m.cursor > (m.end-m.start)/2 && m.viewport.YOffset > 0:
 		m.viewport.SetYOffset(clamp(m.viewport.YOffset-n, 1, m.cursor))
@@ -393,12 +711,14 @@ func (m *Model) GotoTop() {
 
 // GotoBottom moves the selection to the last row.
 func (m *Model) GotoBottom() {
-	m.MoveDown(len(m.rows))
+	m.MoveDown(m.viewLen())
 }
 
 // FromValues create the table rows from a simple string. It uses `\n` by
 // default for getting all the rows and the given separator for the fields on
 // each row.
+//
+// Fields aren't unquoted; use Import to read CSV, TSV or JSON data.
 func (m *Model) FromValues(value, separator string) {
 	rows := []Row{}
 	for _, line := range strings.Split(value, "\n") {
@@ -413,38 +733,104 @@ func (m *Model) FromValues(value, separator string) {
 }
 
 func (m Model) headersView() string {
-	s := make([]string, 0, len(m.cols))
-	for _, col := range m.cols {
-		if col.Width <= 0 {
-			continue
+	visible := m.visibleColumns()
+	s := make([]string, 0, len(visible))
+	for _, i := range visible {
+		title := m.cols[i].Title
+		if m.sorted && m.sortCol == i {
+			if m.sortDesc {
+				title += " ↓"
+			} else {
+				title += " ↑"
+			}
 		}
-		style := lipgloss.NewStyle().Width(col.Width).MaxWidth(col.Width).Inline(true)
-		renderedCell := style.Render(runewidth.Truncate(col.Title, col.Width, "…"))
-		s = append(s, m.styles.Header.Render(renderedCell))
+		s = append(s, m.styles.Header.Render(m.renderCell(i, title)))
 	}
 	return lipgloss.JoinHorizontal(lipgloss.Top, s...)
 }
 
-func (m *Model) renderRow(r int) string {
-	s := make([]string, 0, len(m.cols))
-	for i, value := range m.rows[r] {
-		if m.cols[i].Width <= 0 {
+// renderRow renders the row displayed at v, showing the given columns.
+func (m *Model) renderRow(v int, visible []int) string {
+	r := m.dataIndex(v)
+	if r < 0 {
+		return m.renderViewRow(v, visible)
+	}
+
+	s := make([]string, 0, len(visible))
+
+	values := m.row(r)
+	if values == nil && m.source != nil {
+		for _, i := range visible {
+			s = append(s, m.styles.Cell.Render(m.styles.Placeholder.Render(m.renderCell(i, "…"))))
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Top, s...)
+	}
+
+	for _, i := range visible {
+		if m.editing && v == m.cursor && i == m.colCursor {
+			w := m.colWidth(i)
+			input := lipgloss.NewStyle().Width(w).MaxWidth(w).Inline(true).Render(m.input.View())
+			s = append(s, m.styles.Cell.Render(input))
 			continue
 		}
-		style := lipgloss.NewStyle().Width(m.cols[i].Width).MaxWidth(m.cols[i].Width).Inline(true)
-		renderedCell := m.styles.Cell.Render(style.Render(runewidth.Truncate(value, m.cols[i].Width, "…")))
-		s = append(s, renderedCell)
+		var value string
+		if i < len(values) {
+			value = values[i]
+		}
+		if i == 0 {
+			value = m.treePrefix(r) + value
+		}
+		s = append(s, m.styles.Cell.Render(m.cellStyle(r, i).Render(m.renderCell(i, value))))
 	}
 
 	row := lipgloss.JoinHorizontal(lipgloss.Top, s...)
 
-	if r == m.cursor {
+	if m.IsSelected(r) {
+		row = m.styles.Marked.Render(row)
+	}
+
+	if v == m.cursor {
 		return m.styles.Selected.Render(row)
 	}
 
 	return row
 }
 
+// keys returns the key map with the bindings of features that are turned off
+// disabled. KeyMap itself is left as configured, so bindings disabled there
+// stay disabled whichever features are turned on.
+func (m Model) keys() KeyMap {
+	km := m.KeyMap
+	tree := m.tree != nil && !m.cellNavigation
+	edit := m.cellNavigation && m.editable
+	for _, b := range []struct {
+		binding *key.Binding
+		on      bool
+	}{
+		{&km.ScrollLeft, !m.cellNavigation},
+		{&km.ScrollRight, !m.cellNavigation},
+		{&km.Expand, tree},
+		{&km.Collapse, tree},
+		{&km.CellLeft, m.cellNavigation},
+		{&km.CellRight, m.cellNavigation},
+		{&km.Edit, edit},
+		{&km.CommitEdit, edit},
+		{&km.CancelEdit, edit},
+		{&km.ToggleGroup, m.grouping != nil},
+		{&km.ToggleSelect, m.multiSelect},
+		{&km.SelectUp, m.multiSelect},
+		{&km.SelectDown, m.multiSelect},
+		{&km.SelectAll, m.multiSelect},
+		{&km.SelectNone, m.multiSelect},
+		{&km.InvertSelection, m.multiSelect},
+	} {
+		if !b.on {
+			b.binding.SetEnabled(false)
+		}
+	}
+	return km
+}
+
 func clamp(v, low, high int) int {
 	return min(max(v, low), high)
 }
@@ -1,11 +1,15 @@
 package table
 
 import (
+	"errors"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/charmbracelet/x/exp/golden"
@@ -250,7 +254,7 @@ func TestModel_RenderRow(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			row := tc.table.renderRow(0)
+			row := tc.table.renderRow(0, tc.table.visibleColumns())
 			if row != tc.expected {
 				t.Fatalf("\n\nWant: \n%s\n\nGot:  \n%s\n", tc.expected, row)
 			}
@@ -735,3 +739,817 @@ func TestModel_View_CenteredInABox(t *testing.T) {
 
 	golden.RequireEqual(t, []byte(got))
 }
+
+func TestModel_MultiSelect(t *testing.T) {
+	rows := []Row{{"r1"}, {"r2"}, {"r3"}, {"r4"}}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		table := New(WithColumns(testCols), WithRows(rows), WithFocused(true))
+		table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+
+		if got := table.SelectedIndices(); len(got) != 0 {
+			t.Fatalf("want no selection, got %v", got)
+		}
+	})
+
+	t.Run("keys", func(t *testing.T) {
+		table := New(WithColumns(testCols), WithRows(rows), WithFocused(true), WithMultiSelect(true))
+
+		table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+		table, _ = table.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+		table, _ = table.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+
+		want := []int{0, 1, 2}
+		if got := table.SelectedIndices(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+
+		table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+		want = []int{3}
+		if got := table.SelectedIndices(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+
+		table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+		if got := table.SelectedRows(); !reflect.DeepEqual(got, rows) {
+			t.Fatalf("want %v, got %v", rows, got)
+		}
+
+		table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+		if got := table.SelectedIndices(); len(got) != 0 {
+			t.Fatalf("want no selection, got %v", got)
+		}
+	})
+
+	t.Run("space", func(t *testing.T) {
+		space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+
+		table := New(WithColumns(testCols), WithRows(rows), WithFocused(true), WithHeight(2), WithMultiSelect(true))
+		table, _ = table.Update(space)
+		if got, want := table.SelectedIndices(), []int{0}; !reflect.DeepEqual(got, want) || table.Cursor() != 0 {
+			t.Fatalf("want %v selected on row 0, got %v on row %d", want, got, table.Cursor())
+		}
+
+		// Without multi-select, space keeps paging down.
+		table = New(WithColumns(testCols), WithRows(rows), WithFocused(true), WithHeight(2))
+		table, _ = table.Update(space)
+		if len(table.SelectedIndices()) != 0 || table.Cursor() == 0 {
+			t.Fatalf("want space to page down, got cursor %d", table.Cursor())
+		}
+	})
+
+	t.Run("disabled bindings stay disabled", func(t *testing.T) {
+		km := DefaultKeyMap()
+		km.ToggleSelect.SetEnabled(false)
+		for _, opts := range [][]Option{
+			{WithKeyMap(km), WithMultiSelect(true)},
+			{WithMultiSelect(true), WithKeyMap(km)},
+		} {
+			table := New(append(opts, WithColumns(testCols), WithRows(rows), WithFocused(true))...)
+			table.Help.ShowAll = true
+			table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+			if got := table.SelectedIndices(); len(got) != 0 {
+				t.Fatalf("want no selection, got %v", got)
+			}
+			if strings.Contains(table.HelpView(), "toggle select") {
+				t.Fatalf("want toggle select hidden from help, got %q", table.HelpView())
+			}
+		}
+	})
+
+	t.Run("SetRows drops stale indices", func(t *testing.T) {
+		table := New(WithColumns(testCols), WithRows(rows), WithMultiSelect(true))
+		table.SelectRange(3, 1)
+		table.SetRows(rows[:2])
+
+		want := []int{1}
+		if got := table.SelectedIndices(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+}
+
+func TestModel_ColumnWidths(t *testing.T) {
+	tests := map[string]struct {
+		width int
+		cols  []Column
+		want  []int
+	}{
+		"Fixed": {
+			cols: []Column{{Title: "a", Width: 4}, {Title: "b", Width: 0}},
+			want: []int{4, 0},
+		},
+		"Fit": {
+			cols: []Column{{Title: "a", Fit: true}, {Title: "bb", Fit: true, MaxWidth: 3}},
+			want: []int{5, 3},
+		},
+		"Flex": {
+			width: 30,
+			cols:  []Column{{Title: "a", Width: 4}, {Title: "b", Flex: 1}, {Title: "c", Flex: 2}},
+			want:  []int{4, 6, 14},
+		},
+		"Flex with bounds": {
+			width: 30,
+			cols:  []Column{{Title: "a", Width: 4}, {Title: "b", Flex: 1, MinWidth: 8}, {Title: "c", Flex: 2, MaxWidth: 5}},
+			want:  []int{4, 8, 5},
+		},
+		"Flex without width": {
+			cols: []Column{{Title: "abc", Flex: 1}},
+			want: []int{3},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			table := New(
+				WithWidth(tc.width),
+				WithColumns(tc.cols),
+				WithRows([]Row{{"12345", "123456"}}),
+			)
+			if got := table.ColumnWidths(); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestModel_ColumnWidths_FitUpdates(t *testing.T) {
+	table := New(
+		WithColumns([]Column{{Title: "a", Fit: true}}),
+		WithRows([]Row{{"123"}}),
+	)
+	if got, want := table.ColumnWidths(), []int{3}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	table.SetRows([]Row{{"12345"}})
+	if got, want := table.ColumnWidths(), []int{5}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v after SetRows, got %v", want, got)
+	}
+
+	table.SetColumns([]Column{{Title: "abcdefg", Fit: true}})
+	if got, want := table.ColumnWidths(), []int{7}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v after SetColumns, got %v", want, got)
+	}
+}
+
+func TestModel_HorizontalScroll(t *testing.T) {
+	s := DefaultStyles()
+	s.Header = lipgloss.NewStyle()
+	s.Cell = lipgloss.NewStyle()
+
+	table := New(
+		WithWidth(12),
+		WithHeight(2),
+		WithStyles(s),
+		WithFrozenColumns(1),
+		WithColumns([]Column{
+			{Title: "ID", Width: 4},
+			{Title: "One", Width: 4, Align: lipgloss.Right},
+			{Title: "Two", Width: 4},
+			{Title: "Three", Width: 4},
+		}),
+		WithRows([]Row{{"1", "a", "b", "c"}}),
+	)
+
+	if got, want := table.headersView(), "ID   OneTwo "; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+
+	table.ScrollRight(5)
+	if got, want := table.ColumnOffset(), 1; got != want {
+		t.Fatalf("want offset %d, got %d", want, got)
+	}
+	if got, want := table.headersView(), "ID  Two Thr…"; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+	if got, want := ansi.Strip(table.renderRow(0, table.visibleColumns())), "1   b   c   "; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+
+	table.ScrollLeft(5)
+	if got, want := table.ColumnOffset(), 0; got != want {
+		t.Fatalf("want offset %d, got %d", want, got)
+	}
+}
+
+func TestModel_CellEditing(t *testing.T) {
+	errNotEmpty := errors.New("value must not be empty")
+
+	table := New(
+		WithColumns(testCols),
+		WithRows([]Row{{"a", "b", "c"}, {"d", "e", "f"}}),
+		WithFocused(true),
+		WithCellNavigation(true),
+		WithEditing(true),
+		WithValidateFunc(func(_, _ int, value string) error {
+			if value == "" {
+				return errNotEmpty
+			}
+			return nil
+		}),
+	)
+
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRight})
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if got := table.SelectedCell(); got != "e" {
+		t.Fatalf("want selected cell %q, got %q", "e", got)
+	}
+
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !table.Editing() {
+		t.Fatal("want table to be editing")
+	}
+
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	table, cmd := table.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !errors.Is(table.EditError(), errNotEmpty) {
+		t.Fatalf("want validation error, got %v", table.EditError())
+	}
+
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("xy")})
+	table, cmd = table.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if table.Editing() {
+		t.Fatal("want edit to be committed")
+	}
+
+	want := EditCommittedMsg{Row: 1, Col: 1, Value: "xy", Previous: "e"}
+	if got := cmd(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	if got := table.Rows()[1][1]; got != "xy" {
+		t.Fatalf("want cell to be updated, got %q", got)
+	}
+}
+
+func TestModel_StyleFunc(t *testing.T) {
+	table := &Model{
+		rows:   []Row{{"ok", "fail"}},
+		cols:   testCols[:2],
+		styles: Styles{Cell: lipgloss.NewStyle()},
+		styleFunc: func(_, col int) lipgloss.Style {
+			if col == 1 {
+				return lipgloss.NewStyle().Transform(strings.ToUpper)
+			}
+			return lipgloss.NewStyle()
+		},
+	}
+
+	if got, want := table.renderRow(0, table.visibleColumns()), "ok        FAIL      "; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
+
+type countingSource struct {
+	n       int
+	fetched int
+}
+
+func (s *countingSource) Len() int { return s.n }
+
+func (s *countingSource) Rows(start, end int) []Row {
+	rows := make([]Row, 0, end-start)
+	for i := start; i < end; i++ {
+		rows = append(rows, Row{strconv.Itoa(i)})
+	}
+	s.fetched += len(rows)
+	return rows
+}
+
+func TestModel_DataSource(t *testing.T) {
+	t.Run("sync", func(t *testing.T) {
+		src := &countingSource{n: 1_000_000}
+		table := New(WithColumns(testCols), WithHeight(10), WithDataSource(src))
+
+		if got, want := table.RowCount(), src.n; got != want {
+			t.Fatalf("want %d rows, got %d", want, got)
+		}
+		if src.fetched > defaultPageSize {
+			t.Fatalf("want at most one page fetched, got %d rows", src.fetched)
+		}
+
+		table.SetCursor(500_000)
+		if got, want := table.SelectedRow(), (Row{"500000"}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+
+		table.GotoBottom()
+		if got, want := table.SelectedRow(), (Row{"999999"}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+		if len(table.pages) > maxCachedPages {
+			t.Fatalf("want at most %d cached pages, got %d", maxCachedPages, len(table.pages))
+		}
+	})
+
+	t.Run("async", func(t *testing.T) {
+		src := &countingSource{n: 1_000}
+		table := New(WithColumns(testCols), WithHeight(10), WithAsyncLoading(50), WithDataSource(src))
+
+		if src.fetched != 0 {
+			t.Fatalf("want nothing fetched, got %d rows", src.fetched)
+		}
+		if table.SelectedRow() != nil {
+			t.Fatal("want placeholder row before loading")
+		}
+		if got := ansi.Strip(table.View()); !strings.Contains(got, "…") {
+			t.Fatalf("want placeholder in view, got %q", got)
+		}
+
+		cmd := table.LoadVisibleRows()
+		if cmd == nil {
+			t.Fatal("want command loading the first page")
+		}
+		if table.LoadVisibleRows() != nil {
+			t.Fatal("want no command while the page is loading")
+		}
+
+		before := table
+		table, _ = table.Update(cmd())
+		if got, want := table.SelectedRow(), (Row{"0"}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+		if before.SelectedRow() != nil || len(before.loading) != 1 {
+			t.Fatal("want earlier copies of the model to be unaffected")
+		}
+
+		fetched := src.fetched
+		table.SetMultiSelect(true)
+		table.Select(900)
+		if got, want := table.SelectedRows(), []Row{nil}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+		if src.fetched != fetched {
+			t.Fatal("want SelectedRows not to query the data source")
+		}
+	})
+
+	t.Run("select all", func(t *testing.T) {
+		src := &countingSource{n: 1_000}
+		table := New(WithColumns(testCols), WithHeight(10), WithDataSource(src), WithMultiSelect(true))
+
+		table.SelectAll()
+		table.Deselect(500)
+		if len(table.selected) != 1 {
+			t.Fatalf("want only the deselected row to be tracked, got %d", len(table.selected))
+		}
+		if table.IsSelected(500) || !table.IsSelected(999) || table.IsSelected(1000) {
+			t.Fatal("want every row but 500 to be selected")
+		}
+
+		rows := table.SelectedRows()
+		if got, want := len(rows), 999; got != want {
+			t.Fatalf("want %d rows, got %d", want, got)
+		}
+		if got, want := rows[500], (Row{"501"}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+
+		table.InvertSelection()
+		if got, want := table.SelectedIndices(), []int{500}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+}
+
+func TestModel_ImportExport(t *testing.T) {
+	tests := map[string]struct {
+		format Format
+		input  string
+	}{
+		"CSV": {
+			format: CSV,
+			input:  "Name,Note\nTim Tams,\"chocolate, \"\"coated\"\"\"\nHobnobs,\"two\nlines\"\n",
+		},
+		"TSV": {
+			format: TSV,
+			input:  "Name\tNote\nTim Tams\t\"chocolate, \"\"coated\"\"\"\nHobnobs\t\"two\nlines\"\n",
+		},
+		"JSON": {
+			format: JSON,
+			input: `[
+  {"Name": "Tim Tams", "Note": "chocolate, \"coated\""},
+  {"Name": "Hobnobs", "Note": "two\nlines"}
+]
+`,
+		},
+	}
+
+	wantCols := []Column{{Title: "Name", Width: 8}, {Title: "Note", Width: 19}}
+	wantRows := []Row{
+		{"Tim Tams", `chocolate, "coated"`},
+		{"Hobnobs", "two\nlines"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			table := New()
+			if err := table.Import(strings.NewReader(tc.input), tc.format); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(table.Columns(), wantCols) {
+				t.Fatalf("want columns %v, got %v", wantCols, table.Columns())
+			}
+			if !reflect.DeepEqual(table.Rows(), wantRows) {
+				t.Fatalf("want rows %q, got %q", wantRows, table.Rows())
+			}
+
+			var buf strings.Builder
+			if err := table.Export(&buf, tc.format); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tc.input {
+				t.Fatalf("want output\n%s\ngot\n%s", tc.input, buf.String())
+			}
+		})
+	}
+}
+
+func TestDecode_JSONValues(t *testing.T) {
+	input := `[{"id": 1, "ok": true}, {"id": 2, "tags": ["a"], "ok": null}]`
+
+	cols, rows, err := Decode(strings.NewReader(input), JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantCols := []Column{{Title: "id", Width: 2}, {Title: "ok", Width: 4}, {Title: "tags", Width: 5}}
+	if !reflect.DeepEqual(cols, wantCols) {
+		t.Fatalf("want columns %v, got %v", wantCols, cols)
+	}
+	wantRows := []Row{{"1", "true", ""}, {"2", "", `["a"]`}}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Fatalf("want rows %q, got %q", wantRows, rows)
+	}
+}
+
+func TestModel_Mouse(t *testing.T) {
+	newTable := func() Model {
+		s := DefaultStyles()
+		s.Header = lipgloss.NewStyle().Padding(0, 1)
+		s.Cell = lipgloss.NewStyle().Padding(0, 1)
+		return New(
+			WithFocused(true),
+			WithPosition(2, 1),
+			WithHeaderSort(true),
+			WithStyles(s),
+			WithColumns([]Column{{Title: "Name", Width: 8}, {Title: "Qty", Width: 4}}),
+			WithRows([]Row{{"b", "10"}, {"a", "9"}, {"c", "100"}}),
+		)
+	}
+	press := func(x, y int) tea.MouseMsg {
+		return tea.MouseMsg{X: x, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft}
+	}
+
+	t.Run("click row", func(t *testing.T) {
+		table := newTable()
+		table, _ = table.Update(press(4, 3))
+		if got, want := table.Cursor(), 1; got != want {
+			t.Fatalf("want cursor %d, got %d", want, got)
+		}
+	})
+
+	t.Run("wheel", func(t *testing.T) {
+		table := newTable()
+		table, _ = table.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+		if got, want := table.Cursor(), 2; got != want {
+			t.Fatalf("want cursor %d, got %d", want, got)
+		}
+	})
+
+	t.Run("click header", func(t *testing.T) {
+		table := newTable()
+		table, cmd := table.Update(press(13, 1))
+		if got, want := cmd(), (HeaderClickedMsg{Col: 1}); got != want {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+		want := []Row{{"a", "9"}, {"b", "10"}, {"c", "100"}}
+		if !reflect.DeepEqual(table.Rows(), want) {
+			t.Fatalf("want rows %v, got %v", want, table.Rows())
+		}
+		if got := table.SelectedRow(); !reflect.DeepEqual(got, Row{"b", "10"}) {
+			t.Fatalf("want cursor to follow its row, got %v", got)
+		}
+
+		table, _ = table.Update(press(13, 1))
+		if col, desc, ok := table.SortColumn(); col != 1 || !desc || !ok {
+			t.Fatalf("want descending sort on column 1, got %d %v %v", col, desc, ok)
+		}
+	})
+
+	t.Run("resize column", func(t *testing.T) {
+		table := newTable()
+		// The first column spans x 2-11, so its border is at x 11.
+		table, _ = table.Update(press(11, 1))
+		table, _ = table.Update(tea.MouseMsg{X: 14, Y: 1, Action: tea.MouseActionMotion, Button: tea.MouseButtonLeft})
+		table, cmd := table.Update(tea.MouseMsg{X: 14, Y: 1, Action: tea.MouseActionRelease})
+
+		if got, want := cmd(), (ColumnResizedMsg{Col: 0, Width: 11}); got != want {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+		if got, want := table.ColumnWidths(), []int{11, 4}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("want widths %v, got %v", want, got)
+		}
+	})
+
+	t.Run("resize handle", func(t *testing.T) {
+		// The last character of the first column's content is at x 10 and
+		// the second column's left padding at x 12.
+		table := newTable()
+		table, cmd := table.Update(press(10, 1))
+		if table.resizing || cmd == nil {
+			t.Fatal("want a click on the content to click the header")
+		}
+
+		table = newTable()
+		table, _ = table.Update(press(12, 1))
+		if !table.resizing || table.resizeCol != 0 {
+			t.Fatalf("want the first column to be resized, got %v %d", table.resizing, table.resizeCol)
+		}
+	})
+}
+
+func TestSortBy_MixedValues(t *testing.T) {
+	table := New(
+		WithColumns([]Column{{Title: "Value", Width: 5}}),
+		WithRows([]Row{{"b"}, {"10"}, {""}, {"a"}, {"9"}, {"NaN"}, {"-1.5"}}),
+	)
+
+	table.SortBy(0, false)
+	want := []Row{{"-1.5"}, {"9"}, {"10"}, {""}, {"NaN"}, {"a"}, {"b"}}
+	if got := table.Rows(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+}
+
+func TestModel_Grouping(t *testing.T) {
+	s := DefaultStyles()
+	s.Header = lipgloss.NewStyle()
+	s.Cell = lipgloss.NewStyle()
+
+	newTable := func(g Grouping) Model {
+		return New(
+			WithFocused(true),
+			WithStyles(s),
+			WithHeight(12),
+			WithColumns([]Column{{Title: "Team", Width: 6}, {Title: "Name", Width: 6}, {Title: "Score", Width: 6}}),
+			WithRows([]Row{
+				{"red", "ann", "3"},
+				{"blue", "bob", "5"},
+				{"red", "cid", "10"},
+			}),
+			WithGrouping(g),
+		)
+	}
+
+	t.Run("view", func(t *testing.T) {
+		table := newTable(Grouping{
+			Aggregators: map[int]Aggregator{1: AggregateCount, 2: AggregateSum},
+			Footers:     true,
+			GrandTotal:  true,
+		})
+
+		want := strings.Join([]string{
+			"Team  Name  Score ",
+			"▾ red (2)         ",
+			"red   ann   3     ",
+			"red   cid   10    ",
+			"      2     13    ",
+			"▾ blue (1)        ",
+			"blue  bob   5     ",
+			"      1     5     ",
+			"      3     18    ",
+		}, "\n")
+		got := ansi.Strip(table.View())
+		if lines := strings.Split(got, "\n"); strings.Join(lines[:9], "\n") != want {
+			t.Fatalf("want\n%s\n\ngot\n%s", want, got)
+		}
+	})
+
+	t.Run("collapse", func(t *testing.T) {
+		table := newTable(Grouping{})
+		if table.SelectedRow() != nil {
+			t.Fatalf("want cursor on group header, got %v", table.SelectedRow())
+		}
+
+		table, _ = table.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if !table.IsGroupCollapsed("red") {
+			t.Fatal("want group to be collapsed")
+		}
+
+		table, _ = table.Update(tea.KeyMsg{Type: tea.KeyDown})
+		if key, _ := table.SelectedGroup(); key != "blue" {
+			t.Fatalf("want cursor on next group, got %q", key)
+		}
+		table, _ = table.Update(tea.KeyMsg{Type: tea.KeyDown})
+		if got, want := table.SelectedRow(), (Row{"blue", "bob", "5"}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+
+	t.Run("skip headers", func(t *testing.T) {
+		table := newTable(Grouping{SkipHeaders: true, Footers: true})
+		if got, want := table.SelectedRow(), (Row{"red", "ann", "3"}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+
+		table.MoveDown(2)
+		if got, want := table.SelectedRow(), (Row{"blue", "bob", "5"}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+
+		table.MoveUp(1)
+		if got, want := table.SelectedRow(), (Row{"red", "cid", "10"}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("want %v, got %v", want, got)
+		}
+	})
+
+	t.Run("SetRows with fewer rows", func(t *testing.T) {
+		rows := []Row{{"A"}, {"B"}, {"B"}, {"B"}, {"B"}, {"A"}}
+		table := New(WithColumns(testCols), WithRows(rows), WithGrouping(Grouping{Column: 0}))
+		table.SetCursor(2)
+		table.SetRows(rows[:3])
+
+		if key, ok := table.SelectedGroup(); !ok || key != "A" {
+			t.Fatalf("want cursor to stay in group A, got %q %v", key, ok)
+		}
+	})
+}
+
+func TestModel_Tree(t *testing.T) {
+	s := DefaultStyles()
+	s.Header = lipgloss.NewStyle()
+	s.Cell = lipgloss.NewStyle()
+
+	lazy := &Node{Row: Row{"billing"}, HasChildren: true}
+	tree := []*Node{
+		{
+			Row:      Row{"shop"},
+			Expanded: true,
+			Children: []*Node{
+				{Row: Row{"api"}, Children: []*Node{{Row: Row{"api-1"}}}},
+				lazy,
+			},
+		},
+		{Row: Row{"blog"}},
+	}
+
+	var requested *Node
+	table := New(
+		WithFocused(true),
+		WithStyles(s),
+		WithHeight(8),
+		WithColumns([]Column{{Title: "Name", Width: 12}}),
+		WithTree(tree),
+		WithLoadChildren(func(n *Node) tea.Cmd {
+			requested = n
+			return func() tea.Msg {
+				return ChildrenLoadedMsg{Node: n, Children: []*Node{{Row: Row{"invoices"}}}}
+			}
+		}),
+	)
+
+	view := func() string {
+		lines := strings.Split(ansi.Strip(table.View()), "\n")
+		return strings.Join(lines[1:1+table.RowCount()], "\n")
+	}
+
+	want := "▾ shop      \n  ▸ api     \n  ▸ billing \n  blog      "
+	if got := view(); got != want {
+		t.Fatalf("want\n%s\n\ngot\n%s", want, got)
+	}
+
+	// Expand "api" and move into its child.
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyDown})
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRight})
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if got, want := table.SelectedRow(), (Row{"api-1"}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	// Collapse moves to the parent first, then collapses it.
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if got := table.SelectedNode(); got != tree[0].Children[0] || got.Expanded {
+		t.Fatalf("want collapsed api node, got %v", got)
+	}
+
+	// Lazily load the children of "billing".
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyDown})
+	table, cmd := table.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if requested != lazy || cmd == nil {
+		t.Fatal("want children to be requested")
+	}
+	if got := view(); !strings.Contains(got, "⋯ billing") {
+		t.Fatalf("want loading indicator, got\n%s", got)
+	}
+
+	table, _ = table.Update(cmd())
+	want = "▾ shop      \n  ▸ api     \n  ▾ billing \n      invoi…\n  blog      "
+	if got := view(); got != want {
+		t.Fatalf("want\n%s\n\ngot\n%s", want, got)
+	}
+
+	// Sorting leaves the nodes in their original order.
+	table.SortBy(0, true)
+	want = "▾ shop      \n  ▾ billing \n      invoi…\n  ▸ api     \n  blog      "
+	if got := view(); got != want {
+		t.Fatalf("want\n%s\n\ngot\n%s", want, got)
+	}
+	if tree[0].Children[0].Row[0] != "api" || table.Tree()[0] != tree[0] {
+		t.Fatal("want the nodes to keep their order")
+	}
+	table.ClearSort()
+	want = "▾ shop      \n  ▸ api     \n  ▾ billing \n      invoi…\n  blog      "
+	if got := view(); got != want {
+		t.Fatalf("want\n%s\n\ngot\n%s", want, got)
+	}
+}
+
+func TestModel_Tree_Scroll(t *testing.T) {
+	tree := []*Node{
+		{Row: Row{"shop", "a", "b"}, Expanded: true, Children: []*Node{{Row: Row{"api", "c", "d"}}}},
+		{Row: Row{"blog", "e", "f"}},
+	}
+	table := New(
+		WithFocused(true),
+		WithWidth(12),
+		WithHeight(4),
+		WithColumns([]Column{{Title: "Name", Width: 8}, {Title: "A", Width: 8}, {Title: "B", Width: 8}}),
+		WithTree(tree),
+	)
+
+	// Right moves into the children of an expanded node, and scrolls on a
+	// leaf.
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if got, want := table.SelectedNode(), tree[0].Children[0]; got != want || table.ColumnOffset() != 0 {
+		t.Fatalf("want the cursor on api, got %v with offset %d", got.Row, table.ColumnOffset())
+	}
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if got, want := table.ColumnOffset(), 1; got != want {
+		t.Fatalf("want offset %d, got %d", want, got)
+	}
+
+	// Left moves to the parent of a nested node, and scrolls on a collapsed
+	// root.
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if got, want := table.SelectedNode(), tree[0]; got != want || table.ColumnOffset() != 1 {
+		t.Fatalf("want the cursor on shop, got %v with offset %d", got.Row, table.ColumnOffset())
+	}
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyDown})
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyDown})
+	table, _ = table.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if got, want := table.ColumnOffset(), 0; got != want || !tree[0].Expanded {
+		t.Fatalf("want offset %d with shop expanded, got %d", want, got)
+	}
+}
+
+func TestModel_Tree_LoadingShared(t *testing.T) {
+	lazy := &Node{Row: Row{"billing"}, HasChildren: true}
+	other := &Node{Row: Row{"reports"}, HasChildren: true}
+	table := New(
+		WithColumns([]Column{{Title: "Name", Width: 12}}),
+		WithTree([]*Node{lazy, other}),
+		WithLoadChildren(func(n *Node) tea.Cmd {
+			return func() tea.Msg {
+				return ChildrenLoadedMsg{Node: n, Children: []*Node{{Row: Row{"invoices"}}}}
+			}
+		}),
+	)
+
+	// Expanding a node in a copy of the model leaves the original's loading
+	// state alone.
+	table.ExpandNode(other)
+	expanded := table
+	cmd := expanded.ExpandNode(lazy)
+	if len(table.loadingNodes) != 1 || len(expanded.loadingNodes) != 2 {
+		t.Fatalf("want only the copy to be loading, got %d and %d", len(table.loadingNodes), len(expanded.loadingNodes))
+	}
+
+	loaded, _ := expanded.Update(cmd())
+	if len(expanded.loadingNodes) != 2 || len(loaded.loadingNodes) != 1 {
+		t.Fatalf("want only the updated copy to be done loading, got %d and %d", len(expanded.loadingNodes), len(loaded.loadingNodes))
+	}
+}
+
+func TestModel_Tree_Options(t *testing.T) {
+	t.Run("no LoadChildrenFunc", func(t *testing.T) {
+		n := &Node{Row: Row{"lazy"}, HasChildren: true}
+		table := New(WithColumns(testCols), WithTree([]*Node{n}))
+		if cmd := table.ExpandNode(n); cmd != nil || n.Expanded {
+			t.Fatal("want node not to be expanded")
+		}
+	})
+
+	t.Run("WithRows leaves tree mode", func(t *testing.T) {
+		rows := []Row{{"a"}, {"b"}}
+		table := New(
+			WithColumns(testCols),
+			WithTree([]*Node{{Row: Row{"root"}}}),
+			WithRows(rows),
+		)
+		if table.Tree() != nil || !reflect.DeepEqual(table.Rows(), rows) {
+			t.Fatalf("want rows %v outside tree mode, got %v", rows, table.Rows())
+		}
+	})
+}
@@ -0,0 +1,318 @@
+package table
+
+import (
+	"maps"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Node is a row in tree mode. A node is rendered with its children indented
+// below it while it's expanded.
+type Node struct {
+	Row      Row
+	Children []*Node
+
+	// HasChildren marks a node whose children haven't been loaded yet. They
+	// are requested with the table's LoadChildrenFunc when the node is first
+	// expanded.
+	HasChildren bool
+
+	Expanded bool
+}
+
+// hasChildren reports whether the node has, or may have, children.
+func (n *Node) hasChildren() bool {
+	return len(n.Children) > 0 || n.HasChildren
+}
+
+// LoadChildrenFunc returns a command loading the children of a node. The
+// command should return a ChildrenLoadedMsg.
+type LoadChildrenFunc func(n *Node) tea.Cmd
+
+// ChildrenLoadedMsg delivers the children of a node loaded lazily. If Err is
+// set, the node is collapsed again.
+type ChildrenLoadedMsg struct {
+	Node     *Node
+	Children []*Node
+	Err      error
+}
+
+// WithTree puts the table in tree mode, displaying the given nodes and their
+// expanded descendants.
+func WithTree(nodes []*Node) Option {
+	return func(m *Model) {
+		m.tree = nodes
+		m.flattenTree()
+	}
+}
+
+// WithLoadChildren sets the function used to load the children of nodes
+// lazily.
+func WithLoadChildren(fn LoadChildrenFunc) Option {
+	return func(m *Model) {
+		m.loadChildren = fn
+	}
+}
+
+// SetTree puts the table in tree mode, displaying the given nodes and their
+// expanded descendants. Use SetRows to leave tree mode.
+func (m *Model) SetTree(nodes []*Node) {
+	m.tree = nodes
+	m.source = nil
+	m.pages = nil
+	m.loading = nil
+	m.loadingNodes = nil
+	m.flattenTree()
+	m.UpdateViewport()
+}
+
+// SetLoadChildren sets the function used to load the children of nodes
+// lazily.
+func (m *Model) SetLoadChildren(fn LoadChildrenFunc) {
+	m.loadChildren = fn
+}
+
+// Tree returns the root nodes in tree mode.
+func (m Model) Tree() []*Node {
+	return m.tree
+}
+
+// SelectedNode returns the node under the cursor in tree mode.
+func (m Model) SelectedNode() *Node {
+	if i := m.cursorRow(); i >= 0 && i < len(m.nodes) {
+		return m.nodes[i]
+	}
+	return nil
+}
+
+// NodeDepth returns the depth of the node displayed at the given row index,
+// zero being the root level.
+func (m Model) NodeDepth(i int) int {
+	if i < 0 || i >= len(m.depths) {
+		return 0
+	}
+	return m.depths[i]
+}
+
+// ExpandNode expands a node. If its children haven't been loaded yet, the
+// returned command loads them; without a LoadChildrenFunc such a node can't
+// be expanded.
+func (m *Model) ExpandNode(n *Node) tea.Cmd {
+	if n == nil || !n.hasChildren() || n.Expanded {
+		return nil
+	}
+	if len(n.Children) == 0 && m.loadChildren == nil {
+		return nil
+	}
+	n.Expanded = true
+
+	var cmd tea.Cmd
+	if len(n.Children) == 0 {
+		if _, ok := m.loadingNodes[n]; !ok {
+			loading := make(map[*Node]struct{}, len(m.loadingNodes)+1)
+			maps.Copy(loading, m.loadingNodes)
+			loading[n] = struct{}{}
+			m.loadingNodes = loading
+			cmd = m.loadChildren(n)
+		}
+	}
+
+	m.flattenTree()
+	m.UpdateViewport()
+	return cmd
+}
+
+// CollapseNode collapses a node, hiding its descendants.
+func (m *Model) CollapseNode(n *Node) {
+	if n == nil || !n.Expanded {
+		return
+	}
+	n.Expanded = false
+	m.flattenTree()
+	m.UpdateViewport()
+}
+
+// ToggleNode collapses a node if it's expanded and expands it otherwise.
+func (m *Model) ToggleNode(n *Node) tea.Cmd {
+	if n != nil && n.Expanded {
+		m.CollapseNode(n)
+		return nil
+	}
+	return m.ExpandNode(n)
+}
+
+// canExpandSelected returns whether the Expand keys apply to the node under
+// the cursor: it can be expanded, or it's expanded and has children to move
+// to. Otherwise the keys scroll the table like ScrollRight.
+func (m Model) canExpandSelected() bool {
+	n := m.SelectedNode()
+	if n == nil {
+		return false
+	}
+	if n.Expanded {
+		return len(n.Children) > 0
+	}
+	return n.hasChildren() && (len(n.Children) > 0 || m.loadChildren != nil)
+}
+
+// canCollapseSelected returns whether the Collapse keys apply to the node
+// under the cursor: it's expanded, or it has a parent to move to. Otherwise
+// the keys scroll the table like ScrollLeft.
+func (m Model) canCollapseSelected() bool {
+	n := m.SelectedNode()
+	return n != nil && (n.Expanded || m.depths[m.cursorRow()] > 0)
+}
+
+// expandSelected expands the node under the cursor or, if it's already
+// expanded, moves to its first child.
+func (m *Model) expandSelected() tea.Cmd {
+	n := m.SelectedNode()
+	if n == nil {
+		return nil
+	}
+	if !n.Expanded {
+		return m.ExpandNode(n)
+	}
+	if len(n.Children) > 0 {
+		m.MoveDown(1)
+	}
+	return nil
+}
+
+// collapseSelected collapses the node under the cursor or, if it's already
+// collapsed, moves to its parent.
+func (m *Model) collapseSelected() {
+	n := m.SelectedNode()
+	if n == nil {
+		return
+	}
+	if n.Expanded {
+		m.CollapseNode(n)
+		return
+	}
+	i := m.cursorRow()
+	for p := i - 1; p >= 0; p-- {
+		if m.depths[p] < m.depths[i] {
+			m.MoveUp(i - p)
+			return
+		}
+	}
+}
+
+// handleChildrenLoaded attaches lazily loaded children to their node.
+func (m *Model) handleChildrenLoaded(msg ChildrenLoadedMsg) {
+	if _, ok := m.loadingNodes[msg.Node]; !ok {
+		return
+	}
+	m.loadingNodes = maps.Clone(m.loadingNodes)
+	delete(m.loadingNodes, msg.Node)
+
+	if msg.Err != nil {
+		msg.Node.Expanded = false
+	} else {
+		msg.Node.Children = msg.Children
+		msg.Node.HasChildren = false
+	}
+	m.flattenTree()
+	m.UpdateViewport()
+}
+
+// flattenTree sets the rows to the visible nodes of the tree, keeping the
+// cursor and selection on the same nodes.
+func (m *Model) flattenTree() {
+	if m.tree == nil {
+		return
+	}
+
+	var current *Node
+	if i := m.cursorRow(); i >= 0 && i < len(m.nodes) {
+		current = m.nodes[i]
+	}
+	key, onGroup := m.SelectedGroup()
+	selected := make(map[*Node]struct{}, len(m.selected))
+	for i, n := range m.nodes {
+		if m.IsSelected(i) {
+			selected[n] = struct{}{}
+		}
+	}
+
+	m.nodes, m.depths = nil, nil
+	var walk func(nodes []*Node, depth int)
+	walk = func(nodes []*Node, depth int) {
+		if m.sorted {
+			nodes = m.sortNodes(nodes)
+		}
+		for _, n := range nodes {
+			m.nodes = append(m.nodes, n)
+			m.depths = append(m.depths, depth)
+			if n.Expanded {
+				walk(n.Children, depth+1)
+			}
+		}
+	}
+	walk(m.tree, 0)
+
+	m.rows = make([]Row, len(m.nodes))
+	m.fitWidths = nil
+	m.selected, m.selectInverted = nil, false
+	anchor := -1
+	for i, n := range m.nodes {
+		m.rows[i] = n.Row
+		if _, ok := selected[n]; ok {
+			if m.selected == nil {
+				m.selected = make(map[int]struct{})
+			}
+			m.selected[i] = struct{}{}
+		}
+		if n == current {
+			anchor = i
+		}
+	}
+
+	if anchor < 0 {
+		// The node under the cursor was hidden; stay in place.
+		anchor = clamp(m.cursorRow(), 0, len(m.rows)-1)
+	}
+	m.regroup(anchor, key, onGroup)
+}
+
+// sortNodes returns a copy of the given siblings sorted by the sort column.
+// The nodes themselves are left in their original order, so that the order
+// is restored when the sort is cleared.
+func (m Model) sortNodes(nodes []*Node) []*Node {
+	sorted := append([]*Node(nil), nodes...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		x, y := cellValue(sorted[a].Row, m.sortCol), cellValue(sorted[b].Row, m.sortCol)
+		if m.sortDesc {
+			return lessValue(y, x)
+		}
+		return lessValue(x, y)
+	})
+	return sorted
+}
+
+// treePrefix returns the indentation and expand/collapse glyph rendered in
+// front of the first column of row i in tree mode.
+func (m Model) treePrefix(i int) string {
+	if m.tree == nil || i < 0 || i >= len(m.nodes) {
+		return ""
+	}
+	n := m.nodes[i]
+	glyph := "  "
+	switch {
+	case n.Expanded && len(n.Children) == 0 && m.isLoadingNode(n):
+		glyph = "⋯ "
+	case n.Expanded && n.hasChildren():
+		glyph = "▾ "
+	case n.hasChildren():
+		glyph = "▸ "
+	}
+	return strings.Repeat("  ", m.depths[i]) + glyph
+}
+
+func (m Model) isLoadingNode(n *Node) bool {
+	_, ok := m.loadingNodes[n]
+	return ok
+}